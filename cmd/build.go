@@ -0,0 +1,467 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// maxPTSTime is the maximum 33-bit pts_time.
+const maxPTSTime = 1<<33 - 1
+
+// segmentationTypes maps the names accepted by --seg-type to
+// segmentation_type_ids.
+var segmentationTypes = map[string]uint32{
+	"not-indicated":                scte35.SegmentationTypeNotIndicated,
+	"content-identification":       scte35.SegmentationTypeContentIdentification,
+	"program-start":                scte35.SegmentationTypeProgramStart,
+	"program-end":                  scte35.SegmentationTypeProgramEnd,
+	"program-early-termination":    scte35.SegmentationTypeProgramEarlyTermination,
+	"program-breakaway":            scte35.SegmentationTypeProgramBreakaway,
+	"program-resumption":           scte35.SegmentationTypeProgramResumption,
+	"program-runover-planned":      scte35.SegmentationTypeProgramRunoverPlanned,
+	"program-runover-unplanned":    scte35.SegmentationTypeProgramRunoverUnplanned,
+	"program-overlap-start":        scte35.SegmentationTypeProgramOverlapStart,
+	"program-blackout-override":    scte35.SegmentationTypeProgramBlackoutOverride,
+	"program-start-in-progress":    scte35.SegmentationTypeProgramStartInProgress,
+	"chapter-start":                scte35.SegmentationTypeChapterStart,
+	"chapter-end":                  scte35.SegmentationTypeChapterEnd,
+	"break-start":                  scte35.SegmentationTypeBreakStart,
+	"break-end":                    scte35.SegmentationTypeBreakEnd,
+	"opening-credit-start":         scte35.SegmentationTypeOpeningCreditStart,
+	"opening-credit-end":           scte35.SegmentationTypeOpeningCreditEnd,
+	"closing-credit-start":         scte35.SegmentationTypeClosingCreditStart,
+	"closing-credit-end":           scte35.SegmentationTypeClosingCreditEnd,
+	"provider-ad-start":            scte35.SegmentationTypeProviderAdStart,
+	"provider-ad-end":              scte35.SegmentationTypeProviderAdEnd,
+	"distributor-ad-start":         scte35.SegmentationTypeDistributorAdStart,
+	"distributor-ad-end":           scte35.SegmentationTypeDistributorAdEnd,
+	"provider-po-start":            scte35.SegmentationTypeProviderPOStart,
+	"provider-po-end":              scte35.SegmentationTypeProviderPOEnd,
+	"distributor-po-start":         scte35.SegmentationTypeDistributorPOStart,
+	"distributor-po-end":           scte35.SegmentationTypeDistributorPOEnd,
+	"provider-overlay-po-start":    scte35.SegmentationTypeProviderOverlayPOStart,
+	"provider-overlay-po-end":      scte35.SegmentationTypeProviderOverlayPOEnd,
+	"distributor-overlay-po-start": scte35.SegmentationTypeDistributorOverlayPOStart,
+	"distributor-overlay-po-end":   scte35.SegmentationTypeDistributorOverlayPOEnd,
+	"provider-promo-start":         scte35.SegmentationTypeProviderPromoStart,
+	"provider-promo-end":           scte35.SegmentationTypeProviderPromoEnd,
+	"distributor-promo-start":      scte35.SegmentationTypeDistributorPromoStart,
+	"distributor-promo-end":        scte35.SegmentationTypeDistributorPromoEnd,
+	"unscheduled-event-start":      scte35.SegmentationTypeUnscheduledEventStart,
+	"unscheduled-event-end":        scte35.SegmentationTypeUnscheduledEventEnd,
+	"alt-con-opp-start":            scte35.SegmentationTypeAltConOppStart,
+	"alt-con-opp-end":              scte35.SegmentationTypeAltConOppEnd,
+	"provider-ad-block-start":      scte35.SegmentationTypeProviderAdBlockStart,
+	"provider-ad-block-end":        scte35.SegmentationTypeProviderAdBlockEnd,
+	"distributor-ad-block-start":   scte35.SegmentationTypeDistributorAdBlockStart,
+	"distributor-ad-block-end":     scte35.SegmentationTypeDistributorAdBlockEnd,
+	"network-start":                scte35.SegmentationTypeNetworkStart,
+	"network-end":                  scte35.SegmentationTypeNetworkEnd,
+}
+
+// upidTypes maps the names accepted by --upid to segmentation_upid_types.
+var upidTypes = map[string]uint32{
+	"user": scte35.SegmentationUPIDTypeUserDefined,
+	"isci": scte35.SegmentationUPIDTypeISCI,
+	"adid": scte35.SegmentationUPIDTypeAdID,
+	"umid": scte35.SegmentationUPIDTypeUMID,
+	"isan": scte35.SegmentationUPIDTypeISAN,
+	"tid":  scte35.SegmentationUPIDTypeTID,
+	"ti":   scte35.SegmentationUPIDTypeTI,
+	"adi":  scte35.SegmentationUPIDTypeADI,
+	"eidr": scte35.SegmentationUPIDTypeEIDR,
+	"atsc": scte35.SegmentationUPIDTypeATSC,
+	"ads":  scte35.SegmentationUPIDTypeADS,
+	"uri":  scte35.SegmentationUPIDTypeURI,
+	"uuid": scte35.SegmentationUPIDTypeUUID,
+	"scr":  scte35.SegmentationUPIDTypeSCR,
+}
+
+// buildOptions are the values used by `scte35 build`.
+type buildOptions struct {
+	commandType      string
+	pts              string
+	ptsAdjustment    string
+	tier             uint32
+	eventID          uint32
+	segType          string
+	upids            []string
+	duration         string
+	segmentNum       uint32
+	segmentsExpected uint32
+	cancel           bool
+	outOfNetwork     bool
+	autoReturn       bool
+	uniqueProgramID  uint32
+}
+
+// buildCommand returns the command for `scte35 build`
+func buildCommand() *cobra.Command {
+	var format string
+	var interactive bool
+	opts := buildOptions{}
+	cmd := &cobra.Command{
+		Use:   "build",
+		Short: "Build a splice_info_section from flags or prompts",
+		Long: "Build a splice_info_section from flags, or with --interactive by\n" +
+			"prompting for any values not provided as flags. The signal is printed as\n" +
+			"base-64 and hexadecimal followed by its table.\n\n" +
+			"Times (--pts, --pts-adjustment and --duration) are either durations\n" +
+			"(ie, 1h2m3.5s) or 90KHz ticks. Omitting --pts signals an immediate\n" +
+			"splice.\n\n" +
+			"--seg-type adds a segmentation_descriptor to a time_signal and accepts\n" +
+			"a segmentation_type_id or one of:\n\n" +
+			"  " + wrapNames(segmentationTypes, 76, "  ") + "\n\n" +
+			"--upid may be repeated (signalling a MID()) and is <type>:<value>, where\n" +
+			"type is a segmentation_upid_type or one of:\n\n" +
+			"  " + wrapNames(upidTypes, 76, "  "),
+		Example: "  scte35 build --type time_signal --pts 1h --seg-type provider-ad-start \\\n" +
+			"    --upid adid:ABCD01234567 --duration 30s\n" +
+			"  scte35 build --type splice_insert --pts 1h --duration 30s --event-id 100",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, _ []string) error {
+			if interactive {
+				if err := promptBuildOptions(c, c.InOrStdin(), c.ErrOrStderr(), &opts); err != nil {
+					return err
+				}
+			}
+			sis, err := buildSignal(c, &opts)
+			if err != nil {
+				return err
+			}
+
+			switch format {
+			case "json":
+				b, _ := json.MarshalIndent(sis, "", "\t")
+				_, err = fmt.Fprintf(c.OutOrStdout(), "%s\n", b)
+			case "xml":
+				b, _ := xml.MarshalIndent(sis, "", "\t")
+				_, err = fmt.Fprintf(c.OutOrStdout(), "%s\n", b)
+			default:
+				_, err = fmt.Fprintf(c.OutOrStdout(), "Base64: %s\nHex   : %s\n\n%s\n", sis.Base64(), sis.Hex(), sis.Table("", "\t"))
+			}
+			return err
+		},
+	}
+	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	cmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "prompt for values not provided as flags")
+	cmd.Flags().StringVar(&opts.commandType, "type", "time_signal", "splice_command (time_signal, splice_insert or splice_null)")
+	cmd.Flags().StringVar(&opts.pts, "pts", "", "pts_time, as a duration or ticks (default immediate)")
+	cmd.Flags().StringVar(&opts.ptsAdjustment, "pts-adjustment", "0", "pts_adjustment, as a duration or ticks")
+	cmd.Flags().Uint32Var(&opts.tier, "tier", 0xFFF, "tier")
+	cmd.Flags().Uint32Var(&opts.eventID, "event-id", 1, "splice_event_id or segmentation_event_id")
+	cmd.Flags().StringVar(&opts.segType, "seg-type", "", "segmentation_type_id (time_signal)")
+	cmd.Flags().StringArrayVar(&opts.upids, "upid", nil, "segmentation_upid as <type>:<value> (time_signal)")
+	cmd.Flags().StringVar(&opts.duration, "duration", "", "segmentation_duration or break_duration, as a duration or ticks")
+	cmd.Flags().Uint32Var(&opts.segmentNum, "segment-num", 0, "segment_num (time_signal) or avail_num (splice_insert)")
+	cmd.Flags().Uint32Var(&opts.segmentsExpected, "segments-expected", 0, "segments_expected (time_signal) or avails_expected (splice_insert)")
+	cmd.Flags().BoolVar(&opts.cancel, "cancel", false, "set the event cancel indicator")
+	cmd.Flags().BoolVar(&opts.outOfNetwork, "out-of-network", true, "out_of_network_indicator (splice_insert)")
+	cmd.Flags().BoolVar(&opts.autoReturn, "auto-return", true, "break_duration auto_return (splice_insert)")
+	cmd.Flags().Uint32Var(&opts.uniqueProgramID, "unique-program-id", 0, "unique_program_id (splice_insert)")
+	return cmd
+}
+
+// buildSignal returns the SpliceInfoSection described by opts.
+func buildSignal(c *cobra.Command, opts *buildOptions) (*scte35.SpliceInfoSection, error) {
+	pts, err := parseOptionalTicks("--pts", opts.pts)
+	if err != nil {
+		return nil, err
+	}
+	ptsAdjustment, err := parseOptionalTicks("--pts-adjustment", opts.ptsAdjustment)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := parseOptionalTicks("--duration", opts.duration)
+	if err != nil {
+		return nil, err
+	}
+	if err := check33Bits("--pts", "pts_time", pts); err != nil {
+		return nil, err
+	}
+	if err := check33Bits("--pts-adjustment", "pts_adjustment", ptsAdjustment); err != nil {
+		return nil, err
+	}
+
+	switch opts.commandType {
+	case "time_signal":
+		var ptsTime uint64
+		if pts != nil {
+			ptsTime = *pts
+		}
+		b := scte35.NewTimeSignalBuilder(ptsTime).WithTier(opts.tier)
+		if ptsAdjustment != nil {
+			b.WithPTSAdjustment(*ptsAdjustment)
+		}
+		if opts.segType == "" {
+			if len(opts.upids) > 0 || duration != nil || opts.cancel {
+				return nil, fmt.Errorf("--seg-type is required with --upid, --duration or --cancel")
+			}
+		} else {
+			segmentationTypeID, err := parseSegmentationType(opts.segType)
+			if err != nil {
+				return nil, err
+			}
+			b.WithSegmentation(opts.eventID, segmentationTypeID)
+			if opts.cancel {
+				b.WithCancel()
+			}
+			if duration != nil {
+				b.WithDuration(scte35.TicksToDuration(*duration))
+			}
+			if c.Flags().Changed("segment-num") || c.Flags().Changed("segments-expected") {
+				b.WithSegmentNum(opts.segmentNum, opts.segmentsExpected)
+			}
+			for _, s := range opts.upids {
+				upid, err := parseUPID(s)
+				if err != nil {
+					return nil, err
+				}
+				b.WithUPID(upid)
+			}
+		}
+		sis, err := b.Build()
+		if err != nil {
+			return nil, err
+		}
+		if pts == nil {
+			sis.SpliceCommand = &scte35.TimeSignal{}
+		}
+		return sis, nil
+	case "splice_insert":
+		if opts.segType != "" || len(opts.upids) > 0 {
+			return nil, fmt.Errorf("--seg-type and --upid require --type time_signal")
+		}
+		if err := check33Bits("--duration", "break_duration", duration); err != nil {
+			return nil, err
+		}
+		si := &scte35.SpliceInsert{
+			SpliceEventID:              opts.eventID,
+			SpliceEventCancelIndicator: opts.cancel,
+		}
+		if !opts.cancel {
+			si.OutOfNetworkIndicator = opts.outOfNetwork
+			si.SpliceImmediateFlag = pts == nil
+			si.Program = &scte35.SpliceInsertProgram{}
+			if pts != nil {
+				si.Program = scte35.NewSpliceInsertProgram(*pts)
+			}
+			if duration != nil {
+				si.BreakDuration = &scte35.BreakDuration{AutoReturn: opts.autoReturn, Duration: *duration}
+			}
+			si.UniqueProgramID = opts.uniqueProgramID
+			si.AvailNum = opts.segmentNum
+			si.AvailsExpected = opts.segmentsExpected
+		}
+		return newSignal(si, opts, ptsAdjustment), nil
+	case "splice_null":
+		if opts.segType != "" || len(opts.upids) > 0 {
+			return nil, fmt.Errorf("--seg-type and --upid require --type time_signal")
+		}
+		return newSignal(&scte35.SpliceNull{}, opts, ptsAdjustment), nil
+	default:
+		return nil, fmt.Errorf("unsupported --type: %q", opts.commandType)
+	}
+}
+
+// newSignal returns a SpliceInfoSection for sc with the same defaults as
+// scte35.NewTimeSignalBuilder.
+func newSignal(sc scte35.SpliceCommand, opts *buildOptions, ptsAdjustment *uint64) *scte35.SpliceInfoSection {
+	sis := &scte35.SpliceInfoSection{
+		SpliceCommand:   sc,
+		EncryptedPacket: scte35.EncryptedPacket{EncryptionAlgorithm: scte35.EncryptionAlgorithmNone, CWIndex: 0xFF},
+		SAPType:         scte35.SAPTypeNotSpecified,
+		Tier:            opts.tier,
+	}
+	if ptsAdjustment != nil {
+		sis.PTSAdjustment = *ptsAdjustment
+	}
+	return sis
+}
+
+// parseOptionalTicks parses a duration (ie, 30s) or a number of 90KHz ticks,
+// returning nil if s is empty.
+func parseOptionalTicks(flag, s string) (*uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	ticks, err := strconv.ParseUint(s, 0, 64)
+	if err != nil {
+		d, derr := time.ParseDuration(s)
+		if derr != nil || d < 0 {
+			return nil, fmt.Errorf("%s: invalid duration or ticks: %q", flag, s)
+		}
+		ticks = scte35.DurationToTicks(d)
+	}
+	return &ticks, nil
+}
+
+// check33Bits returns an error if ticks exceeds the 33 bits of a pts_time,
+// pts_adjustment or break_duration. The 40 bit segmentation_duration is
+// checked by scte35.TimeSignalBuilder.
+func check33Bits(flag, field string, ticks *uint64) error {
+	if ticks != nil && *ticks > maxPTSTime {
+		return fmt.Errorf("%s: %s %d exceeds 33 bits", flag, field, *ticks)
+	}
+	return nil
+}
+
+// parseSegmentationType parses a segmentation_type_id name or value.
+func parseSegmentationType(s string) (uint32, error) {
+	if id, ok := segmentationTypes[strings.ToLower(s)]; ok {
+		return id, nil
+	}
+	id, err := strconv.ParseUint(s, 0, 8)
+	if err != nil {
+		return 0, fmt.Errorf("--seg-type: unknown segmentation_type_id: %q", s)
+	}
+	return uint32(id), nil
+}
+
+// parseUPID parses a <type>:<value> segmentation_upid.
+func parseUPID(s string) (scte35.SegmentationUPID, error) {
+	name, value, ok := strings.Cut(s, ":")
+	if !ok {
+		return scte35.SegmentationUPID{}, fmt.Errorf("--upid: expected <type>:<value>: %q", s)
+	}
+	upidType, ok := upidTypes[strings.ToLower(name)]
+	if !ok {
+		t, err := strconv.ParseUint(name, 0, 8)
+		if err != nil {
+			return scte35.SegmentationUPID{}, fmt.Errorf("--upid: unknown segmentation_upid_type: %q", name)
+		}
+		upidType = uint32(t)
+	}
+
+	format := scte35.SegmentationUPIDFormatText
+	if upidType == scte35.SegmentationUPIDTypeATSC || upidType == scte35.SegmentationUPIDTypeMPU {
+		format = scte35.SegmentationUPIDFormatBase64
+	}
+	return scte35.SegmentationUPID{Type: upidType, Format: format, Value: value}, nil
+}
+
+// promptBuildOptions prompts for each option not set by a flag.
+func promptBuildOptions(c *cobra.Command, r io.Reader, w io.Writer, opts *buildOptions) error {
+	br := bufio.NewReader(r)
+	prompt := func(flag, label string, value *string) error {
+		if c.Flags().Changed(flag) {
+			return nil
+		}
+		if *value != "" {
+			_, _ = fmt.Fprintf(w, "%s [%s]: ", label, *value)
+		} else {
+			_, _ = fmt.Fprintf(w, "%s: ", label)
+		}
+		line, err := br.ReadString('\n')
+		switch {
+		case errors.Is(err, io.EOF) && line == "":
+			return fmt.Errorf("%s: %w", label, io.ErrUnexpectedEOF)
+		case err != nil && !errors.Is(err, io.EOF):
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		if line = strings.TrimSpace(line); line != "" {
+			*value = line
+		}
+		return nil
+	}
+	promptUint32 := func(flag, label string, value *uint32) error {
+		s := strconv.FormatUint(uint64(*value), 10)
+		if err := prompt(flag, label, &s); err != nil {
+			return err
+		}
+		v, err := strconv.ParseUint(s, 0, 32)
+		if err != nil {
+			return fmt.Errorf("%s: %w", label, err)
+		}
+		*value = uint32(v)
+		return nil
+	}
+
+	if err := prompt("type", "splice_command (time_signal, splice_insert, splice_null)", &opts.commandType); err != nil {
+		return err
+	}
+	if opts.commandType == "splice_null" {
+		return nil
+	}
+	if err := prompt("pts", "pts_time (duration or ticks, empty for immediate)", &opts.pts); err != nil {
+		return err
+	}
+	if err := promptUint32("event-id", "event_id", &opts.eventID); err != nil {
+		return err
+	}
+	if opts.commandType == "time_signal" {
+		if err := prompt("seg-type", "segmentation_type_id (ie, provider-ad-start)", &opts.segType); err != nil {
+			return err
+		}
+		if opts.segType == "" {
+			return nil
+		}
+		if !c.Flags().Changed("upid") {
+			var upid string
+			if err := prompt("upid", "segmentation_upid (<type>:<value>, empty for none)", &upid); err != nil {
+				return err
+			}
+			if upid != "" {
+				opts.upids = append(opts.upids, upid)
+			}
+		}
+	}
+	return prompt("duration", "duration (duration or ticks, empty for none)", &opts.duration)
+}
+
+// wrapNames returns the sorted keys of m, separated by commas and wrapped at
+// width with each continuation line prefixed by indent.
+func wrapNames[T any](m map[string]T, width int, indent string) string {
+	names := make([]string, 0, len(m))
+	for k := range m {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	line := len(indent)
+	for i, n := range names {
+		if i > 0 {
+			sb.WriteString(",")
+			line++
+			if line+1+len(n) > width {
+				sb.WriteString("\n" + indent)
+				line = len(indent)
+			} else {
+				sb.WriteString(" ")
+				line++
+			}
+		}
+		sb.WriteString(n)
+		line += len(n)
+	}
+	return sb.String()
+}
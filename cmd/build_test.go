@@ -0,0 +1,337 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Comcast/scte35-go/cmd"
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuild(t *testing.T) {
+	const hour = uint64(324000000) // 1h in 90KHz ticks
+	adID := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD01234567"}
+	ti := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeTI, Format: scte35.SegmentationUPIDFormatText, Value: "748724618"}
+
+	timeSignal := func(pts *uint64, f func(b *scte35.TimeSignalBuilder)) *scte35.SpliceInfoSection {
+		var ptsTime uint64
+		if pts != nil {
+			ptsTime = *pts
+		}
+		b := scte35.NewTimeSignalBuilder(ptsTime).WithTier(0xFFF)
+		if f != nil {
+			f(b)
+		}
+		sis, err := b.Build()
+		require.NoError(t, err)
+		if pts == nil {
+			sis.SpliceCommand = &scte35.TimeSignal{}
+		}
+		return sis
+	}
+	signal := func(sc scte35.SpliceCommand, ptsAdjustment uint64) *scte35.SpliceInfoSection {
+		return &scte35.SpliceInfoSection{
+			SpliceCommand:   sc,
+			EncryptedPacket: scte35.EncryptedPacket{EncryptionAlgorithm: scte35.EncryptionAlgorithmNone, CWIndex: 0xFF},
+			SAPType:         scte35.SAPTypeNotSpecified,
+			PTSAdjustment:   ptsAdjustment,
+			Tier:            0xFFF,
+		}
+	}
+
+	cases := map[string]struct {
+		args     []string
+		in       io.Reader
+		expected *scte35.SpliceInfoSection
+		err      string
+	}{
+		"Defaults": {
+			expected: timeSignal(nil, nil),
+		},
+		"Time Signal": {
+			args: []string{"--pts", "1h", "--pts-adjustment", "900", "--seg-type", "provider-ad-start", "--upid", "adid:ABCD01234567", "--duration", "30s", "--event-id", "5"},
+			expected: timeSignal(ptr(hour), func(b *scte35.TimeSignalBuilder) {
+				b.WithPTSAdjustment(900).WithSegmentation(5, scte35.SegmentationTypeProviderAdStart).WithUPID(adID).WithDuration(30 * time.Second)
+			}),
+		},
+		"Time Signal MID": {
+			args: []string{"--seg-type", "0x34", "--upid", "adid:ABCD01234567", "--upid", "8:748724618", "--segment-num", "1", "--segments-expected", "2", "--duration", "2700000"},
+			expected: timeSignal(nil, func(b *scte35.TimeSignalBuilder) {
+				b.WithSegmentation(1, scte35.SegmentationTypeProviderPOStart).WithUPID(adID).WithUPID(ti).WithSegmentNum(1, 2).WithDuration(30 * time.Second)
+			}),
+		},
+		"Time Signal Cancel": {
+			args: []string{"--seg-type", "provider-ad-start", "--cancel"},
+			expected: timeSignal(nil, func(b *scte35.TimeSignalBuilder) {
+				b.WithSegmentation(1, scte35.SegmentationTypeProviderAdStart).WithCancel()
+			}),
+		},
+		"Time Signal Segmentation Duration 40 Bits": {
+			args: []string{"--seg-type", "provider-ad-start", "--upid", "adid:ABCD01234567", "--duration", "0x3FFFFFFFF"},
+			expected: timeSignal(nil, func(b *scte35.TimeSignalBuilder) {
+				b.WithSegmentation(1, scte35.SegmentationTypeProviderAdStart).WithUPID(adID).WithDuration(scte35.TicksToDuration(0x3FFFFFFFF))
+			}),
+		},
+		"Splice Insert": {
+			args: []string{"--type", "splice_insert", "--pts", "1h", "--duration", "30s", "--event-id", "100", "--unique-program-id", "2", "--segment-num", "1", "--segments-expected", "3"},
+			expected: signal(&scte35.SpliceInsert{
+				SpliceEventID:         100,
+				OutOfNetworkIndicator: true,
+				Program:               scte35.NewSpliceInsertProgram(hour),
+				BreakDuration:         &scte35.BreakDuration{AutoReturn: true, Duration: 2700000},
+				UniqueProgramID:       2,
+				AvailNum:              1,
+				AvailsExpected:        3,
+			}, 0),
+		},
+		"Splice Insert Immediate": {
+			args: []string{"--type", "splice_insert", "--out-of-network=false", "--auto-return=false", "--duration", "30s"},
+			expected: signal(&scte35.SpliceInsert{
+				SpliceEventID:       1,
+				SpliceImmediateFlag: true,
+				Program:             &scte35.SpliceInsertProgram{},
+				BreakDuration:       &scte35.BreakDuration{Duration: 2700000},
+			}, 0),
+		},
+		"Splice Insert Cancel": {
+			args:     []string{"--type", "splice_insert", "--cancel", "--event-id", "7"},
+			expected: signal(&scte35.SpliceInsert{SpliceEventID: 7, SpliceEventCancelIndicator: true}, 0),
+		},
+		"Splice Null": {
+			args:     []string{"--type", "splice_null", "--pts-adjustment", "10s"},
+			expected: signal(&scte35.SpliceNull{}, 900000),
+		},
+		"Time Signal PTS Overflow": {
+			args: []string{"--pts", "0x200000000"},
+			err:  "--pts: pts_time 8589934592 exceeds 33 bits",
+		},
+		"Time Signal PTS Adjustment Overflow": {
+			args: []string{"--pts-adjustment", "0x200000000"},
+			err:  "--pts-adjustment: pts_adjustment 8589934592 exceeds 33 bits",
+		},
+		"Time Signal Segmentation Duration Overflow": {
+			args: []string{"--seg-type", "provider-ad-start", "--upid", "adid:ABCD01234567", "--duration", "0x10000000000"},
+			err:  "segmentation_descriptor[0]: invalid splice_info_section: segmentation_duration 1099511627776 exceeds 40 bits",
+		},
+		"Splice Insert PTS Overflow": {
+			args: []string{"--type", "splice_insert", "--pts", "0x3FFFFFFFF"},
+			err:  "--pts: pts_time 17179869183 exceeds 33 bits",
+		},
+		"Splice Insert PTS Adjustment Overflow": {
+			args: []string{"--type", "splice_insert", "--pts-adjustment", "0x3FFFFFFFF"},
+			err:  "--pts-adjustment: pts_adjustment 17179869183 exceeds 33 bits",
+		},
+		"Splice Insert Duration Overflow": {
+			args: []string{"--type", "splice_insert", "--duration", "0x3FFFFFFFF"},
+			err:  "--duration: break_duration 17179869183 exceeds 33 bits",
+		},
+		"Splice Null PTS Adjustment Overflow": {
+			args: []string{"--type", "splice_null", "--pts-adjustment", "0x3FFFFFFFF"},
+			err:  "--pts-adjustment: pts_adjustment 17179869183 exceeds 33 bits",
+		},
+		"Invalid Duration": {
+			args: []string{"--duration", "soon"},
+			err:  `--duration: invalid duration or ticks: "soon"`,
+		},
+		"Negative Duration": {
+			args: []string{"--pts", "-1s"},
+			err:  `--pts: invalid duration or ticks: "-1s"`,
+		},
+		"Unsupported Type": {
+			args: []string{"--type", "splice_schedule"},
+			err:  `unsupported --type: "splice_schedule"`,
+		},
+		"Segmentation Without Time Signal": {
+			args: []string{"--type", "splice_insert", "--seg-type", "provider-ad-start"},
+			err:  "--seg-type and --upid require --type time_signal",
+		},
+		"UPID Without Segmentation Type": {
+			args: []string{"--upid", "adid:ABCD01234567"},
+			err:  "--seg-type is required with --upid, --duration or --cancel",
+		},
+		"Unknown Segmentation Type": {
+			args: []string{"--seg-type", "ad-start"},
+			err:  `--seg-type: unknown segmentation_type_id: "ad-start"`,
+		},
+		"Invalid UPID": {
+			args: []string{"--seg-type", "provider-ad-start", "--upid", "ABCD01234567"},
+			err:  `--upid: expected <type>:<value>: "ABCD01234567"`,
+		},
+		"Prompt": {
+			args: []string{"-i"},
+			in:   strings.NewReader("\n1h\n5\nprovider-ad-start\nadid:ABCD01234567\n30s\n"),
+			expected: timeSignal(ptr(hour), func(b *scte35.TimeSignalBuilder) {
+				b.WithSegmentation(5, scte35.SegmentationTypeProviderAdStart).WithUPID(adID).WithDuration(30 * time.Second)
+			}),
+		},
+		"Prompt Defaults": {
+			args:     []string{"-i"},
+			in:       strings.NewReader("\n\n\n\n"),
+			expected: timeSignal(nil, nil),
+		},
+		"Prompt Splice Insert": {
+			args: []string{"-i"},
+			in:   strings.NewReader("splice_insert\n1h\n100\n30s\n"),
+			expected: signal(&scte35.SpliceInsert{
+				SpliceEventID:         100,
+				OutOfNetworkIndicator: true,
+				Program:               scte35.NewSpliceInsertProgram(hour),
+				BreakDuration:         &scte35.BreakDuration{AutoReturn: true, Duration: 2700000},
+			}, 0),
+		},
+		"Prompt Without Trailing Newline": {
+			args:     []string{"-i"},
+			in:       strings.NewReader("splice_null"),
+			expected: signal(&scte35.SpliceNull{}, 0),
+		},
+		"Prompt Flags": {
+			// only the prompts for options without flags are shown
+			args: []string{"-i", "--type", "time_signal", "--pts", "1h", "--seg-type", "provider-ad-start", "--upid", "adid:ABCD01234567"},
+			in:   strings.NewReader("5\n30s\n"),
+			expected: timeSignal(ptr(hour), func(b *scte35.TimeSignalBuilder) {
+				b.WithSegmentation(5, scte35.SegmentationTypeProviderAdStart).WithUPID(adID).WithDuration(30 * time.Second)
+			}),
+		},
+		"Prompt All Flags": {
+			args:     []string{"-i", "--type", "splice_null"},
+			expected: signal(&scte35.SpliceNull{}, 0),
+		},
+		"Prompt EOF": {
+			args: []string{"-i"},
+			in:   strings.NewReader("\n"),
+			err:  "pts_time (duration or ticks, empty for immediate): unexpected EOF",
+		},
+		"Prompt Empty": {
+			args: []string{"-i"},
+			err:  "splice_command (time_signal, splice_insert, splice_null): unexpected EOF",
+		},
+		"Prompt Invalid Event ID": {
+			args: []string{"-i"},
+			in:   strings.NewReader("\n\nfive\n"),
+			err:  `event_id: strconv.ParseUint: parsing "five": invalid syntax`,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, c.in, append([]string{"build", "--out", "json"}, c.args...)...)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+
+			var sis scte35.SpliceInfoSection
+			require.NoError(t, json.Unmarshal([]byte(out), &sis))
+			require.Empty(t, scte35.Diff(c.expected, &sis))
+		})
+	}
+}
+
+func TestParseSegmentationType(t *testing.T) {
+	cases := map[string]struct {
+		s        string
+		expected uint32
+		err      string
+	}{
+		"Name":             {s: "provider-ad-start", expected: scte35.SegmentationTypeProviderAdStart},
+		"Name Upper Case":  {s: "Break-End", expected: scte35.SegmentationTypeBreakEnd},
+		"Decimal":          {s: "52", expected: scte35.SegmentationTypeProviderPOStart},
+		"Hexadecimal":      {s: "0x34", expected: scte35.SegmentationTypeProviderPOStart},
+		"Unknown Value":    {s: "0xFE", expected: 0xFE},
+		"Unknown Name":     {s: "ad-start", err: `--seg-type: unknown segmentation_type_id: "ad-start"`},
+		"Exceeds 8 Bits":   {s: "0x100", err: `--seg-type: unknown segmentation_type_id: "0x100"`},
+		"Empty":            {s: "", err: `--seg-type: unknown segmentation_type_id: ""`},
+		"Negative":         {s: "-1", err: `--seg-type: unknown segmentation_type_id: "-1"`},
+		"Trailing Garbage": {s: "52s", err: `--seg-type: unknown segmentation_type_id: "52s"`},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			id, err := cmd.ParseSegmentationType(c.s)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, id)
+		})
+	}
+}
+
+func TestParseUPID(t *testing.T) {
+	cases := map[string]struct {
+		s        string
+		expected scte35.SegmentationUPID
+		err      string
+	}{
+		"Ad-ID": {
+			s:        "adid:ABCD01234567",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD01234567"},
+		},
+		"Upper Case Type": {
+			s:        "URI:urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeURI, Format: scte35.SegmentationUPIDFormatText, Value: "urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6"},
+		},
+		"Numeric Type": {
+			s:        "0x08:748724618",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeTI, Format: scte35.SegmentationUPIDFormatText, Value: "748724618"},
+		},
+		"ATSC": {
+			s:        "atsc:AAEC",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeATSC, Format: scte35.SegmentationUPIDFormatBase64, Value: "AAEC"},
+		},
+		"MPU": {
+			s:        "12:AAEC",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeMPU, Format: scte35.SegmentationUPIDFormatBase64, Value: "AAEC"},
+		},
+		"Empty Value": {
+			s:        "isci:",
+			expected: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeISCI, Format: scte35.SegmentationUPIDFormatText},
+		},
+		"Missing Type": {
+			s:   "ABCD01234567",
+			err: `--upid: expected <type>:<value>: "ABCD01234567"`,
+		},
+		"Unknown Type": {
+			s:   "ad-id:ABCD01234567",
+			err: `--upid: unknown segmentation_upid_type: "ad-id"`,
+		},
+		"Type Exceeds 8 Bits": {
+			s:   "256:ABCD01234567",
+			err: `--upid: unknown segmentation_upid_type: "256"`,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid, err := cmd.ParseUPID(c.s)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, upid)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// convertCommand returns the command for `scte35 convert`
+func convertCommand() *cobra.Command {
+	var from, to, file, output string
+	cmd := &cobra.Command{
+		Use:   "convert [signal]",
+		Short: "Convert a splice_info_section between representations",
+		Long: "Convert a splice_info_section between representations. The signal is\n" +
+			"read from the argument, --file or stdin, and written to --output or\n" +
+			"stdout.\n\n" +
+			"Supported representations are:\n\n" +
+			"  base64  base-64 encoded binary\n" +
+			"  hex     hexadecimal encoded binary, optionally 0x prefixed\n" +
+			"  binary  raw binary\n" +
+			"  json    JSON\n" +
+			"  xml     SCTE 35 XML <SpliceInfoSection>\n" +
+			"  signal  SCTE 35 XML <Signal><Binary>\n" +
+			"  text    the table printed by decode (output only)\n\n" +
+			"The input representation is detected unless --from is set.",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("requires at most one signal")
+			}
+			if len(args) == 1 && file != "" {
+				return fmt.Errorf("--file cannot be used with a signal argument")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			representations := scte35.Representations()
+			if from != scte35.RepresentationAuto && (from == scte35.RepresentationText || !slices.Contains(representations, from)) {
+				return fmt.Errorf("unsupported --from: %q", from)
+			}
+			if !slices.Contains(representations, to) {
+				return fmt.Errorf("unsupported --to: %q", to)
+			}
+
+			var in []byte
+			switch {
+			case len(args) == 1:
+				in = []byte(args[0])
+			case file != "" && file != "-":
+				b, err := os.ReadFile(file)
+				if err != nil {
+					return err
+				}
+				in = b
+			default:
+				b, err := io.ReadAll(c.InOrStdin())
+				if err != nil {
+					return err
+				}
+				in = b
+			}
+
+			sis, err := scte35.ReadSignal(in, from)
+			if err != nil {
+				return err
+			}
+			out, err := scte35.WriteSignal(sis, to)
+			if err != nil {
+				return err
+			}
+
+			if output != "" && output != "-" {
+				return os.WriteFile(output, out, 0o644)
+			}
+			_, err = c.OutOrStdout().Write(out)
+			return err
+		},
+	}
+	representations := scte35.Representations()
+	cmd.Flags().StringVar(&from, "from", scte35.RepresentationAuto, "input representation ("+strings.Join(representations[:len(representations)-1], ", ")+")")
+	cmd.Flags().StringVar(&to, "to", "", "output representation ("+strings.Join(representations, ", ")+")")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "read the signal from a file instead of stdin (- for stdin)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "write the signal to a file instead of stdout (- for stdout)")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
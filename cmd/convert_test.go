@@ -0,0 +1,133 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvert(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	const hexSignal = "fc3034000000000000fffff00506fe72bd0050001e021c435545494800008e7fcf0001a599b00808000000002ca0a18a3402009ac9d17e"
+
+	bin, err := base64.StdEncoding.DecodeString(signal)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	binFile := filepath.Join(dir, "signal.bin")
+	require.NoError(t, os.WriteFile(binFile, bin, 0o600))
+
+	cases := map[string]struct {
+		args     []string
+		in       io.Reader
+		expected string
+		output   string // file expected to contain expected
+		err      string
+	}{
+		"Argument": {
+			args:     []string{"convert", "--to", "hex", signal},
+			expected: hexSignal,
+		},
+		"Argument From": {
+			args:     []string{"convert", "--from", "hex", "--to", "base64", "0x" + hexSignal},
+			expected: signal,
+		},
+		"Argument From Mismatch": {
+			args: []string{"convert", "--from", "hex", "--to", "base64", signal},
+			err:  "invalid or unsupported encoding",
+		},
+		"File": {
+			args:     []string{"convert", "--file", binFile, "--to", "base64"},
+			expected: signal,
+		},
+		"File Not Found": {
+			args: []string{"convert", "--file", filepath.Join(dir, "missing.bin"), "--to", "base64"},
+			err:  "no such file or directory",
+		},
+		"Stdin": {
+			args:     []string{"convert", "--to", "base64"},
+			in:       bytes.NewReader(bin),
+			expected: signal,
+		},
+		"Stdin Dash": {
+			args:     []string{"convert", "--file", "-", "--to", "binary"},
+			in:       strings.NewReader(signal + "\n"),
+			expected: string(bin),
+		},
+		"Stdin Empty": {
+			args: []string{"convert", "--to", "base64"},
+			err:  "buffer overflow",
+		},
+		"Output": {
+			args:   []string{"convert", "--output", filepath.Join(dir, "signal.hex"), "--to", "hex", signal},
+			output: filepath.Join(dir, "signal.hex"),
+		},
+		"Output Dash": {
+			args:     []string{"convert", "--output", "-", "--to", "hex", signal},
+			expected: hexSignal,
+		},
+		"Output Not Writable": {
+			args: []string{"convert", "--output", filepath.Join(dir, "missing", "signal.hex"), "--to", "hex", signal},
+			err:  "no such file or directory",
+		},
+		"Unsupported From": {
+			args: []string{"convert", "--from", "text", "--to", "hex", signal},
+			err:  `unsupported --from: "text"`,
+		},
+		"Unsupported To": {
+			args: []string{"convert", "--to", "bogus", signal},
+			err:  `unsupported --to: "bogus"`,
+		},
+		"Missing To": {
+			args: []string{"convert", signal},
+			err:  `required flag(s) "to" not set`,
+		},
+		"Signal With File": {
+			args: []string{"convert", "--file", binFile, "--to", "hex", signal},
+			err:  "--file cannot be used with a signal argument",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, c.in, c.args...)
+			if c.err != "" {
+				require.ErrorContains(t, err, c.err)
+				require.Empty(t, out)
+				return
+			}
+			require.NoError(t, err)
+			if c.output == "" {
+				require.Equal(t, c.expected, strings.TrimSuffix(out, "\n"))
+				return
+			}
+			require.Empty(t, out)
+			b, err := os.ReadFile(c.output)
+			require.NoError(t, err)
+			require.Equal(t, hexSignal, strings.TrimSuffix(string(b), "\n"))
+		})
+	}
+}
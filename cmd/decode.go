@@ -17,9 +17,12 @@
 package cmd
 
 import (
+	"bufio"
+	"encoding/base64"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -30,45 +33,260 @@ import (
 // coreCommand returns the command for `scte35 decode`
 func decodeCommand() *cobra.Command {
 	var format string
+	var file string
+	var binary, annotate bool
+	var color string
 	cmd := &cobra.Command{
-		Use:   "decode",
+		Use:   "decode [signal]",
 		Short: "Decode a splice_info_section from binary",
+		Long: "Decode a splice_info_section from binary.\n\n" +
+			"The signal may be base-64, hexadecimal (optionally 0x or 0X prefixed), JSON\n" +
+			"or XML. If no signal is provided, signals are read one per line from --file\n" +
+			"or, if --file is not set, from stdin until EOF. Use --file - to type signals\n" +
+			"at a terminal. With --binary, the input is read as concatenated raw\n" +
+			"splice_info_sections instead. Each signal is written in the --out format\n" +
+			"(one JSON object per line by default), including any decoding error, and\n" +
+			"the exit code is non-zero if any signal fails to decode.\n\n" +
+			"With --annotate, the text output includes the byte offset (and bit, if\n" +
+			"not byte aligned) and raw hex of each field, along with reserved bits\n" +
+			"and the CRC_32, and marks the fields at which decoding failed.",
 		Args: func(_ *cobra.Command, args []string) error {
-			if len(args) != 1 {
-				return fmt.Errorf("requires a binary signal")
+			if len(args) > 1 {
+				return fmt.Errorf("requires at most one binary signal")
+			}
+			if len(args) == 1 && (file != "" || binary) {
+				return fmt.Errorf("--file and --binary cannot be used with a signal argument")
+			}
+			if annotate && len(args) == 0 {
+				return fmt.Errorf("--annotate requires a signal argument")
+			}
+			if annotate && format != "" && format != "text" {
+				return fmt.Errorf("--annotate requires --out text")
 			}
 			return nil
 		},
-		Run: func(_ *cobra.Command, args []string) {
-			bin := args[0]
-			var sis *scte35.SpliceInfoSection
-			var err error
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				if err := checkStdin(c, file); err != nil {
+					return err
+				}
+				if format == "" {
+					format = "json"
+				}
+				return decodeBatch(c.OutOrStdout(), c.InOrStdin(), file, binary, format)
+			}
+			if annotate {
+				return decodeAnnotated(c.OutOrStdout(), args[0], color)
+			}
 
 			// decode payload
-			if strings.HasPrefix(bin, "0x") {
-				sis, err = scte35.DecodeHex(bin)
-			} else {
-				sis, err = scte35.DecodeBase64(bin)
-			}
+			sis, err := scte35.ReadSignal([]byte(args[0]), scte35.RepresentationAuto)
 
 			// print details (sis is never nil)
 			switch format {
 			case "json":
 				b, _ := json.MarshalIndent(sis, "", "\t")
-				_, _ = fmt.Fprintf(os.Stdout, "%s\n", b)
+				_, _ = fmt.Fprintf(c.OutOrStdout(), "%s\n", b)
 			case "xml":
 				b, _ := xml.MarshalIndent(sis, "", "\t")
-				_, _ = fmt.Fprintf(os.Stdout, "%s\n", b)
+				_, _ = fmt.Fprintf(c.OutOrStdout(), "%s\n", b)
 			default:
-				_, _ = fmt.Fprintf(os.Stdout, "%s\n", sis.Table("", "\t"))
+				_, _ = fmt.Fprintf(c.OutOrStdout(), "%s\n", sis.Table("", "\t"))
 			}
 
 			// and any errors
-			if err != nil {
-				_, _ = fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-			}
+			return err
 		},
 	}
-	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	cmd.PersistentFlags().StringVar(&format, "out", "", "specify alternative output format (json, xml, text; default text, or json when reading signals from --file or stdin)")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "read signals from a file instead of stdin (- for stdin)")
+	cmd.Flags().BoolVar(&binary, "binary", false, "input contains raw binary splice_info_sections")
+	cmd.Flags().BoolVar(&annotate, "annotate", false, "include byte offsets and raw hex in the text output")
+	cmd.Flags().StringVar(&color, "color", "auto", "colorize annotated output (auto, always, never)")
 	return cmd
 }
+
+// decodeAnnotated decodes a signal, writing its annotated table to w. The
+// decoding error, if any, is returned.
+func decodeAnnotated(w io.Writer, s string, color string) error {
+	useColor, err := colorEnabled(color, w)
+	if err != nil {
+		return err
+	}
+	b, err := scte35.SignalBytes([]byte(s), scte35.RepresentationAuto)
+	if err != nil {
+		return err
+	}
+	sis := &scte35.SpliceInfoSection{}
+	err = sis.Decode(b)
+	_, _ = fmt.Fprintf(w, "%s\n", sis.AnnotatedTable(b, "", "\t", useColor))
+	return err
+}
+
+// colorEnabled returns true if output to w should be colorized. In auto mode
+// output is colorized if w is a terminal and NO_COLOR is not set.
+func colorEnabled(mode string, w io.Writer) (bool, error) {
+	switch mode {
+	case "always":
+		return true, nil
+	case "never":
+		return false, nil
+	case "auto":
+		if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+			return false, nil
+		}
+		return isTerminal(w), nil
+	default:
+		return false, fmt.Errorf("unsupported --color: %q", mode)
+	}
+}
+
+// checkStdin returns an error, after printing usage, if signals would be read
+// from a terminal without having been requested with --file -.
+func checkStdin(c *cobra.Command, file string) error {
+	if file == "" && isTerminal(c.InOrStdin()) {
+		_ = c.Usage()
+		return fmt.Errorf("requires a signal, --file or input on stdin")
+	}
+	return nil
+}
+
+// isTerminal returns true if f is a terminal (or other character device).
+func isTerminal(f any) bool {
+	file, ok := f.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := file.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// decodeResult is the representation of a signal decoded in batch mode.
+type decodeResult struct {
+	XMLName           xml.Name                  `xml:"DecodeResult" json:"-"`
+	Index             int                       `xml:"index,attr" json:"index"`
+	Signal            string                    `xml:"signal,attr" json:"signal"`
+	SpliceInfoSection *scte35.SpliceInfoSection `xml:"http://www.scte.org/schemas/35 SpliceInfoSection,omitempty" json:"spliceInfoSection,omitempty"`
+	Error             string                    `xml:"Error,omitempty" json:"error,omitempty"`
+}
+
+// decodeBatch decodes each signal read from file (or stdin), writing the
+// results to w in the given format: one JSON object or XML element per line,
+// or a table per signal. An error is returned if any signal fails to decode.
+func decodeBatch(w io.Writer, stdin io.Reader, file string, binary bool, format string) error {
+	write := func(res *decodeResult) error {
+		switch format {
+		case "json":
+			return json.NewEncoder(w).Encode(res)
+		case "xml":
+			b, err := xml.Marshal(res)
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", b)
+			return err
+		default:
+			_, _ = fmt.Fprintf(w, "# %d: %s\n", res.Index, res.Signal)
+			if res.SpliceInfoSection != nil {
+				_, _ = fmt.Fprintf(w, "%s\n", res.SpliceInfoSection.Table("", "\t"))
+			}
+			if res.Error != "" {
+				_, _ = fmt.Fprintf(w, "Error: %s\n", res.Error)
+			}
+			_, err := fmt.Fprintln(w)
+			return err
+		}
+	}
+
+	total, failed := 0, 0
+	err := readSignals(stdin, file, binary, func(signal string, b []byte, err error) error {
+		res := decodeResult{Index: total, Signal: signal}
+		if err == nil {
+			sis := &scte35.SpliceInfoSection{}
+			err = sis.Decode(b)
+			// omit signals that could not be decoded at all
+			if sis.SpliceCommand != nil {
+				res.SpliceInfoSection = sis
+			}
+		}
+		if err != nil {
+			res.Error = err.Error()
+			failed++
+		}
+		total++
+		return write(&res)
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d signals failed to decode", failed, total)
+	}
+	return nil
+}
+
+// readSignals reads signals from file (or stdin if file is empty or "-"), one
+// per line (see scte35.ReadSignal) or as concatenated raw
+// splice_info_sections if binary is set, and passes each one to f. Signals
+// that cannot be read are passed to f with an error.
+func readSignals(stdin io.Reader, file string, binary bool, f func(signal string, b []byte, err error) error) error {
+	in := stdin
+	if file != "" && file != "-" {
+		fi, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = fi.Close() }()
+		in = fi
+	}
+
+	if binary {
+		b, err := io.ReadAll(in)
+		if err != nil {
+			return err
+		}
+		for len(b) > 0 {
+			n, err := sectionLength(b)
+			if err != nil {
+				// unable to find the next section, so report the remainder
+				return f(base64.StdEncoding.EncodeToString(b), b, err)
+			}
+			if err := f(base64.StdEncoding.EncodeToString(b[:n]), b[:n], nil); err != nil {
+				return err
+			}
+			b = b[n:]
+		}
+		return nil
+	}
+
+	scanner := bufio.NewScanner(in)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		b, err := scte35.SignalBytes([]byte(line), scte35.RepresentationAuto)
+		if err := f(line, b, err); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// sectionLength returns the length, in bytes, of the splice_info_section at
+// the start of b.
+func sectionLength(b []byte) (int, error) {
+	if len(b) < 3 {
+		return 0, fmt.Errorf("truncated splice_info_section: %d bytes", len(b))
+	}
+	if b[0] != scte35.TableID {
+		return 0, fmt.Errorf("invalid table_id: %#02x", b[0])
+	}
+	n := 3 + (int(b[1]&0x0F)<<8 | int(b[2]))
+	if n > len(b) {
+		return 0, fmt.Errorf("truncated splice_info_section: expected %d bytes, got %d", n, len(b))
+	}
+	return n, nil
+}
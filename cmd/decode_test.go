@@ -0,0 +1,360 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/cmd"
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+// decodeResult is the NDJSON record written by `decode` in batch mode.
+type decodeResult struct {
+	Index             int                       `json:"index"`
+	Signal            string                    `json:"signal"`
+	SpliceInfoSection *scte35.SpliceInfoSection `json:"spliceInfoSection"`
+	Error             string                    `json:"error"`
+}
+
+func TestDecode_Batch(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const timeSignal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	const spliceNull = "0xfc301100000000000000fff0000000007a4fbfff"
+
+	timeSignalBytes, err := base64.StdEncoding.DecodeString(timeSignal)
+	require.NoError(t, err)
+	spliceNullBytes, err := hex.DecodeString(spliceNull[2:])
+	require.NoError(t, err)
+
+	file := filepath.Join(t.TempDir(), "signals.txt")
+	require.NoError(t, os.WriteFile(file, []byte(timeSignal+"\n"+spliceNull+"\n"), 0o600))
+
+	type result struct {
+		signal  string
+		command uint32 // 0 if omitted
+		err     string
+	}
+
+	cases := map[string]struct {
+		args     []string
+		in       io.Reader
+		expected []result
+		err      string
+	}{
+		"Stdin": {
+			args: []string{"decode"},
+			in:   strings.NewReader(timeSignal + "\n\n" + spliceNull + "\n"),
+			expected: []result{
+				{signal: timeSignal, command: scte35.TimeSignalType},
+				{signal: spliceNull, command: scte35.SpliceNullType},
+			},
+		},
+		"Stdin Dash": {
+			args: []string{"decode", "--file", "-"},
+			in:   strings.NewReader(timeSignal),
+			expected: []result{
+				{signal: timeSignal, command: scte35.TimeSignalType},
+			},
+		},
+		"File": {
+			args: []string{"decode", "--file", file},
+			expected: []result{
+				{signal: timeSignal, command: scte35.TimeSignalType},
+				{signal: spliceNull, command: scte35.SpliceNullType},
+			},
+		},
+		"File Not Found": {
+			args: []string{"decode", "--file", filepath.Join(t.TempDir(), "missing.txt")},
+			err:  "no such file or directory",
+		},
+		"Failure": {
+			args: []string{"decode"},
+			in:   strings.NewReader("bogus\n" + timeSignal + "\n0xfc30\n"),
+			expected: []result{
+				{signal: "bogus", err: scte35.ErrUnsupportedEncoding.Error()},
+				{signal: timeSignal, command: scte35.TimeSignalType},
+				{signal: "0xfc30", err: "splice_info_section: " + scte35.ErrBufferOverflow.Error()},
+			},
+			err: "2 of 3 signals failed to decode",
+		},
+		"Binary": {
+			args: []string{"decode", "--binary"},
+			in:   bytes.NewReader(append(append([]byte{}, timeSignalBytes...), spliceNullBytes...)),
+			expected: []result{
+				{signal: timeSignal, command: scte35.TimeSignalType},
+				{signal: base64.StdEncoding.EncodeToString(spliceNullBytes), command: scte35.SpliceNullType},
+			},
+		},
+		"Binary Truncated": {
+			args: []string{"decode", "--binary"},
+			in:   bytes.NewReader(append(append([]byte{}, timeSignalBytes...), timeSignalBytes[:10]...)),
+			expected: []result{
+				{signal: timeSignal, command: scte35.TimeSignalType},
+				{signal: base64.StdEncoding.EncodeToString(timeSignalBytes[:10]), err: "truncated splice_info_section: expected 55 bytes, got 10"},
+			},
+			err: "1 of 2 signals failed to decode",
+		},
+		"Binary Invalid Table ID": {
+			args: []string{"decode", "--binary"},
+			in:   bytes.NewReader([]byte{0x00, 0x01, 0x02}),
+			expected: []result{
+				{signal: "AAEC", err: "invalid table_id: 0x00"},
+			},
+			err: "1 of 1 signals failed to decode",
+		},
+		"Signal With File": {
+			args: []string{"decode", "--file", file, timeSignal},
+			err:  "--file and --binary cannot be used with a signal argument",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, c.in, c.args...)
+			if c.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, c.err)
+			}
+
+			var actual []result
+			dec := json.NewDecoder(strings.NewReader(out))
+			for dec.More() {
+				var res decodeResult
+				require.NoError(t, dec.Decode(&res))
+				require.Equal(t, len(actual), res.Index)
+				r := result{signal: res.Signal, err: res.Error}
+				if res.SpliceInfoSection != nil {
+					r.command = res.SpliceInfoSection.SpliceCommand.Type()
+				}
+				actual = append(actual, r)
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestDecode_BatchOut(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const timeSignal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	const spliceNull = "0XFC301100000000000000FFF0000000007A4FBFFF"
+
+	sis, err := scte35.DecodeBase64(timeSignal)
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		out      string
+		expected []string
+	}{
+		"JSON": {
+			out: "json",
+			expected: []string{
+				`{"index":0,"signal":"` + timeSignal + `","spliceInfoSection":{`,
+				`{"index":1,"signal":"` + spliceNull + `","spliceInfoSection":{`,
+				`{"index":2,"signal":"bogus","error":"invalid or unsupported encoding"}`,
+			},
+		},
+		"XML": {
+			out: "xml",
+			expected: []string{
+				`<DecodeResult index="0" signal="` + timeSignal + `"><SpliceInfoSection xmlns="http://www.scte.org/schemas/35"`,
+				`<DecodeResult index="1" signal="` + spliceNull + `"><SpliceInfoSection xmlns="http://www.scte.org/schemas/35"`,
+				`<DecodeResult index="2" signal="bogus"><Error>invalid or unsupported encoding</Error></DecodeResult>`,
+			},
+		},
+		"Text": {
+			out: "text",
+			expected: []string{
+				"# 0: " + timeSignal + "\n" + sis.Table("", "\t") + "\n\n",
+				"# 1: " + spliceNull + "\n",
+				"# 2: bogus\nError: invalid or unsupported encoding\n\n",
+			},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			in := strings.NewReader(timeSignal + "\n" + spliceNull + "\nbogus\n")
+			out, err := execute(t, in, "decode", "--out", c.out)
+			require.EqualError(t, err, "1 of 3 signals failed to decode")
+			for _, s := range c.expected {
+				require.Contains(t, out, s)
+			}
+			if c.out != "text" {
+				require.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 3)
+			}
+		})
+	}
+}
+
+func TestDecode_Terminal(t *testing.T) {
+	// character devices are treated as terminals
+	tty, err := os.Open(os.DevNull)
+	require.NoError(t, err)
+	defer func() { _ = tty.Close() }()
+
+	for _, command := range []string{"decode", "validate"} {
+		_, err = execute(t, tty, command)
+		require.EqualError(t, err, "requires a signal, --file or input on stdin")
+
+		// unless explicitly requested
+		out, err := execute(t, tty, command, "--file", "-")
+		require.NoError(t, err)
+		require.Empty(t, out)
+	}
+}
+
+func TestDecode_Annotate(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	// Sample 14.1, truncated within the segmentation_descriptor
+	const truncated = "0xfc3034000000000000fffff00506fe72bd0050001e021c435545494800008e"
+
+	cases := map[string]struct {
+		args     []string
+		noColor  bool
+		contains []string
+		color    bool
+		err      string
+	}{
+		"Auto": {
+			args:     []string{signal},
+			contains: []string{"0000    fc                \ttable_id: 0xfc\n", "0033    9ac9d17e          CRC_32: 0x9ac9d17e\n"},
+		},
+		"Always": {
+			args:     []string{"--color=always", signal},
+			contains: []string{"fc                \x1b[0m\ttable_id: 0xfc\n"},
+			color:    true,
+		},
+		"Always NO_COLOR": {
+			// NO_COLOR only applies to auto
+			args:    []string{"--color=always", signal},
+			noColor: true,
+			color:   true,
+		},
+		"Never": {
+			args:     []string{"--color=never", signal},
+			contains: []string{"0000    fc                \ttable_id: 0xfc\n"},
+		},
+		"Hex": {
+			args:     []string{"--color=never", "0xfc301100000000000000fff0000000007a4fbfff"},
+			contains: []string{"000d    00                splice_command_type: 0x00\n"},
+		},
+		"Hex Upper Case Prefix": {
+			args:     []string{"--color=never", "0XFC301100000000000000FFF0000000007A4FBFFF"},
+			contains: []string{"000d    00                splice_command_type: 0x00\n"},
+		},
+		"Truncated": {
+			args:     []string{"--color=never", truncated},
+			contains: []string{"001f    --                \tsegmentation_event_cancel_indicator: false  <- error: extends beyond the end of the signal\n"},
+			err:      "segmentation_descriptor: buffer overflow",
+		},
+		"Invalid Encoding": {
+			args: []string{"bogus"},
+			err:  scte35.ErrUnsupportedEncoding.Error(),
+		},
+		"Invalid Color": {
+			args: []string{"--color=sometimes", signal},
+			err:  `unsupported --color: "sometimes"`,
+		},
+		"JSON": {
+			args: []string{"--out", "json", signal},
+			err:  "--annotate requires --out text",
+		},
+		"XML": {
+			args: []string{"--out", "xml", signal},
+			err:  "--annotate requires --out text",
+		},
+		"Batch": {
+			err: "--annotate requires a signal argument",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			if c.noColor {
+				t.Setenv("NO_COLOR", "1")
+			}
+			out, err := execute(t, nil, append([]string{"decode", "--annotate"}, c.args...)...)
+			if c.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, c.err)
+			}
+			for _, s := range c.contains {
+				require.Contains(t, out, s)
+			}
+			require.Equal(t, c.color, strings.Contains(out, "\x1b["))
+		})
+	}
+}
+
+func TestColorEnabled(t *testing.T) {
+	// /dev/null is a character device, like a terminal
+	tty, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0)
+	require.NoError(t, err)
+	defer func() { _ = tty.Close() }()
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	cases := map[string]struct {
+		mode     string
+		w        io.Writer
+		env      map[string]string
+		expected bool
+		err      string
+	}{
+		"Always":              {mode: "always", w: &bytes.Buffer{}, expected: true},
+		"Always NO_COLOR":     {mode: "always", w: file, env: map[string]string{"NO_COLOR": "1"}, expected: true},
+		"Never":               {mode: "never", w: tty},
+		"Auto Terminal":       {mode: "auto", w: tty, expected: true},
+		"Auto NO_COLOR":       {mode: "auto", w: tty, env: map[string]string{"NO_COLOR": "1"}},
+		"Auto Empty NO_COLOR": {mode: "auto", w: tty, env: map[string]string{"NO_COLOR": ""}, expected: true},
+		"Auto Dumb Terminal":  {mode: "auto", w: tty, env: map[string]string{"TERM": "dumb"}},
+		"Auto File":           {mode: "auto", w: file},
+		"Auto Buffer":         {mode: "auto", w: &bytes.Buffer{}},
+		"Unsupported":         {mode: "yes", w: tty, err: `unsupported --color: "yes"`},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			t.Setenv("NO_COLOR", "")
+			t.Setenv("TERM", "xterm")
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+			enabled, err := cmd.ColorEnabled(c.mode, c.w)
+			if c.err != "" {
+				require.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, enabled)
+		})
+	}
+}
@@ -0,0 +1,388 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"io"
+)
+
+const (
+	// tsPacketSize is the size of an MPEG-2 transport stream packet.
+	tsPacketSize = 188
+	// tsSyncByte is the first byte of every transport stream packet.
+	tsSyncByte = 0x47
+	// patPID is the PID carrying the program_association_section.
+	patPID = 0x0000
+	// scte35StreamType is the PMT stream_type for SCTE 35 cues.
+	scte35StreamType = 0x86
+	// registrationDescriptorTag is the tag of an MPEG-2
+	// registration_descriptor.
+	registrationDescriptorTag = 0x05
+)
+
+// tsCue is a splice_info_section extracted from a transport stream.
+type tsCue struct {
+	// Packet is the index of the packet completing the section.
+	Packet int
+	// PID is the PID carrying the section.
+	PID uint16
+	// PCR is the base (90KHz) of the most recent program_clock_reference of
+	// the program carrying the section.
+	PCR *uint64
+	// PTS is the most recent video presentation_time_stamp of the program
+	// carrying the section.
+	PTS *uint64
+	// Section is the binary splice_info_section.
+	Section []byte
+}
+
+// tsProgram is the clock state of a program, as described by its PMT.
+type tsProgram struct {
+	pcrPID   uint16
+	videoPID *uint16
+	pcr      *uint64
+	pts      *uint64
+}
+
+// tsDemuxer extracts SCTE 35 cues from a transport stream, locating the SCTE
+// 35 PIDs via the PAT and PMTs. The PCR and video PTS are tracked per program,
+// so that each cue is stamped with the clock of its own program.
+type tsDemuxer struct {
+	pmtPIDs    map[uint16]bool
+	programs   map[uint16]*tsProgram // by program_number
+	scte35PIDs map[uint16]uint16     // program_number by PID
+	sections   map[uint16][]byte
+	cc         map[uint16]byte
+	packet     int
+}
+
+// newTSDemuxer returns a new tsDemuxer.
+func newTSDemuxer() *tsDemuxer {
+	return &tsDemuxer{
+		pmtPIDs:    map[uint16]bool{},
+		programs:   map[uint16]*tsProgram{},
+		scte35PIDs: map[uint16]uint16{},
+		sections:   map[uint16][]byte{},
+		cc:         map[uint16]byte{},
+		packet:     -1,
+	}
+}
+
+// readTSPackets reads transport stream packets from r, re-synchronizing if
+// necessary, and passes each one to f.
+func readTSPackets(r io.Reader, f func(pkt []byte) error) error {
+	br := bufio.NewReaderSize(r, tsPacketSize*64)
+	pkt := make([]byte, tsPacketSize)
+	synced := false
+	for {
+		// peek at the following sync byte too, in case we need to resync
+		b, err := br.Peek(tsPacketSize + 1)
+		if len(b) < tsPacketSize {
+			if err == nil || errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if !isTSPacketStart(b, synced) {
+			synced = false
+			_, _ = br.Discard(1)
+			continue
+		}
+		synced = true
+		copy(pkt, b)
+		_, _ = br.Discard(tsPacketSize)
+		if err := f(pkt); err != nil {
+			return err
+		}
+	}
+}
+
+// isTSPacketStart returns true if b begins with a transport stream packet.
+// When re-synchronizing (synced is false), a sync byte must also be present
+// at the start of the following packet, if any, so that a 0x47 within a
+// packet is not mistaken for the start of one.
+func isTSPacketStart(b []byte, synced bool) bool {
+	if len(b) < tsPacketSize || b[0] != tsSyncByte {
+		return false
+	}
+	return synced || len(b) == tsPacketSize || b[tsPacketSize] == tsSyncByte
+}
+
+// Write processes a single transport stream packet, returning any cues
+// completed by it.
+func (d *tsDemuxer) Write(pkt []byte) []tsCue {
+	d.packet++
+	if len(pkt) != tsPacketSize || pkt[0] != tsSyncByte {
+		return nil
+	}
+
+	pusi := pkt[1]&0x40 != 0
+	pid := uint16(pkt[1]&0x1F)<<8 | uint16(pkt[2])
+	afc := pkt[3] >> 4 & 0x03
+
+	payload := pkt[4:]
+	if afc&0x02 != 0 {
+		afl := int(pkt[4])
+		if afl > len(payload)-1 {
+			return nil
+		}
+		d.readAdaptationField(pid, payload[1:1+afl])
+		payload = payload[1+afl:]
+	}
+	if afc&0x01 == 0 {
+		return nil
+	}
+
+	// drop duplicate packets and discard partial sections following lost
+	// packets (unless signalled by the discontinuity_indicator).
+	cc := pkt[3] & 0x0F
+	last, ok := d.cc[pid]
+	d.cc[pid] = cc
+	if ok {
+		discontinuity := afc&0x02 != 0 && pkt[4] > 0 && pkt[5]&0x80 != 0
+		switch {
+		case cc == last && !discontinuity:
+			return nil
+		case cc != (last+1)&0x0F:
+			delete(d.sections, pid)
+		}
+	}
+
+	if _, ok := d.scte35PIDs[pid]; ok || pid == patPID || d.pmtPIDs[pid] {
+		return d.readSections(pid, pusi, payload)
+	}
+	if pusi {
+		d.readPESHeader(pid, payload)
+	}
+	return nil
+}
+
+// readAdaptationField records the PCR, if present, for the programs whose
+// PCR_PID is pid.
+func (d *tsDemuxer) readAdaptationField(pid uint16, b []byte) {
+	if len(b) < 7 || b[0]&0x10 == 0 {
+		return
+	}
+	pcr := uint64(b[1])<<25 | uint64(b[2])<<17 | uint64(b[3])<<9 | uint64(b[4])<<1 | uint64(b[5])>>7
+	for _, p := range d.programs {
+		if p.pcrPID == pid {
+			p.pcr = &pcr
+		}
+	}
+}
+
+// readPESHeader records the PTS from a PES header, if present, for the
+// programs whose video PID is pid.
+func (d *tsDemuxer) readPESHeader(pid uint16, b []byte) {
+	if len(b) < 14 || b[0] != 0x00 || b[1] != 0x00 || b[2] != 0x01 || b[7]&0x80 == 0 {
+		return
+	}
+	pts := uint64(b[9]>>1&0x07)<<30 | uint64(b[10])<<22 | uint64(b[11]>>1)<<15 | uint64(b[12])<<7 | uint64(b[13]>>1)
+	for _, p := range d.programs {
+		if p.videoPID != nil && *p.videoPID == pid {
+			p.pts = &pts
+		}
+	}
+}
+
+// readSections reassembles PSI sections carried in the payload, handling the
+// PAT and PMTs and returning any completed SCTE 35 cues.
+func (d *tsDemuxer) readSections(pid uint16, pusi bool, payload []byte) []tsCue {
+	var cues []tsCue
+	if pusi {
+		if len(payload) == 0 {
+			return nil
+		}
+		pointer := int(payload[0])
+		payload = payload[1:]
+		if pointer > len(payload) {
+			return nil
+		}
+		// the bytes before the pointer complete the previous section
+		if len(d.sections[pid]) > 0 {
+			cues = append(cues, d.appendSection(pid, payload[:pointer])...)
+		}
+		d.sections[pid] = []byte{}
+		payload = payload[pointer:]
+	} else if len(d.sections[pid]) == 0 {
+		// waiting for the start of a section
+		return nil
+	}
+	return append(cues, d.appendSection(pid, payload)...)
+}
+
+// appendSection appends b to the section being assembled for pid, handling
+// each section completed.
+func (d *tsDemuxer) appendSection(pid uint16, b []byte) []tsCue {
+	var cues []tsCue
+	buf := append(d.sections[pid], b...)
+	for len(buf) >= 3 {
+		// stuffing bytes follow the last section
+		if buf[0] == 0xFF {
+			buf = buf[:0]
+			break
+		}
+		n := 3 + (int(buf[1]&0x0F)<<8 | int(buf[2]))
+		if len(buf) < n {
+			break
+		}
+		section := buf[:n]
+		buf = buf[n:]
+		programNumber, isSCTE35 := d.scte35PIDs[pid]
+		switch {
+		case pid == patPID && section[0] == 0x00:
+			d.readPAT(section)
+		case d.pmtPIDs[pid] && section[0] == 0x02:
+			d.readPMT(section)
+		case isSCTE35 && section[0] == 0xFC:
+			p := d.programs[programNumber]
+			cues = append(cues, tsCue{
+				Packet:  d.packet,
+				PID:     pid,
+				PCR:     p.pcr,
+				PTS:     p.pts,
+				Section: append([]byte(nil), section...),
+			})
+		}
+	}
+	d.sections[pid] = buf
+	return cues
+}
+
+// readPAT records the PMT PIDs from a program_association_section.
+func (d *tsDemuxer) readPAT(b []byte) {
+	if len(b) < 12 {
+		return
+	}
+	for i := 8; i+4 <= len(b)-4; i += 4 {
+		programNumber := uint16(b[i])<<8 | uint16(b[i+1])
+		if programNumber == 0 {
+			continue // network_PID
+		}
+		d.pmtPIDs[uint16(b[i+2]&0x1F)<<8|uint16(b[i+3])] = true
+	}
+}
+
+// readPMT records the PCR_PID and the SCTE 35 and video PIDs of a program from
+// its TS_program_map_section. The first video stream provides the program's
+// PTS.
+func (d *tsDemuxer) readPMT(b []byte) {
+	if len(b) < 16 {
+		return
+	}
+	programNumber := uint16(b[3])<<8 | uint16(b[4])
+	p := d.programs[programNumber]
+	if p == nil {
+		p = &tsProgram{}
+		d.programs[programNumber] = p
+	}
+	p.pcrPID = uint16(b[8]&0x1F)<<8 | uint16(b[9])
+	p.videoPID = nil
+
+	programInfoLength := int(b[10]&0x0F)<<8 | int(b[11])
+	i := 12 + programInfoLength
+	for i+5 <= len(b)-4 {
+		streamType := b[i]
+		pid := uint16(b[i+1]&0x1F)<<8 | uint16(b[i+2])
+		esInfoLength := int(b[i+3]&0x0F)<<8 | int(b[i+4])
+		esInfo := b[min(i+5, len(b)):min(i+5+esInfoLength, len(b))]
+		switch {
+		case streamType == scte35StreamType, hasCUEIRegistration(esInfo):
+			d.scte35PIDs[pid] = programNumber
+		case isVideoStreamType(streamType) && p.videoPID == nil:
+			p.videoPID = &pid
+		}
+		i += 5 + esInfoLength
+	}
+}
+
+// hasCUEIRegistration returns true if the descriptors include a
+// registration_descriptor with a format_identifier of "CUEI".
+func hasCUEIRegistration(b []byte) bool {
+	for len(b) >= 2 {
+		tag, n := b[0], int(b[1])
+		if len(b) < 2+n {
+			return false
+		}
+		if tag == registrationDescriptorTag && n >= 4 && string(b[2:6]) == "CUEI" {
+			return true
+		}
+		b = b[2+n:]
+	}
+	return false
+}
+
+// isVideoStreamType returns true if streamType identifies a video elementary
+// stream (MPEG-1/2, MPEG-4, AVC or HEVC).
+func isVideoStreamType(streamType byte) bool {
+	switch streamType {
+	case 0x01, 0x02, 0x10, 0x1B, 0x24:
+		return true
+	default:
+		return false
+	}
+}
+
+// datagramPackets returns the transport stream packets carried by a UDP
+// datagram, removing the RTP header (RFC 3550) if the datagram does not begin
+// with a sync byte.
+func datagramPackets(b []byte) [][]byte {
+	if len(b) > 0 && b[0] != tsSyncByte {
+		b = rtpPayload(b)
+	}
+	var pkts [][]byte
+	synced := false
+	for len(b) >= tsPacketSize {
+		if !isTSPacketStart(b, synced) {
+			// re-synchronize
+			synced = false
+			b = b[1:]
+			continue
+		}
+		synced = true
+		pkts = append(pkts, b[:tsPacketSize])
+		b = b[tsPacketSize:]
+	}
+	return pkts
+}
+
+// rtpPayload returns the payload of an RTP packet, or nil if b is not a
+// version 2 RTP packet.
+func rtpPayload(b []byte) []byte {
+	if len(b) < 12 || b[0]>>6 != 2 {
+		return nil
+	}
+	n := 12 + 4*int(b[0]&0x0F)
+	if b[0]&0x10 != 0 {
+		// header extension
+		if len(b) < n+4 {
+			return nil
+		}
+		n += 4 + 4*(int(b[n+2])<<8|int(b[n+3]))
+	}
+	end := len(b)
+	if b[0]&0x20 != 0 {
+		// padding
+		end -= int(b[end-1])
+	}
+	if n > end {
+		return nil
+	}
+	return b[n:end]
+}
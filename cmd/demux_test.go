@@ -0,0 +1,319 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	// pmtPID is the PID of the synthetic PMT.
+	pmtPID = 0x100
+	// scte35PID is the PID of the synthetic SCTE 35 elementary stream.
+	scte35PID = 0x1F4
+)
+
+// tsPacket returns a transport stream packet carrying payload (which must not
+// exceed 184 bytes), padded with stuffing bytes.
+func tsPacket(pid uint16, pusi bool, cc byte, payload []byte) []byte {
+	pkt := bytes.Repeat([]byte{0xFF}, 188)
+	pkt[0] = 0x47
+	pkt[1] = byte(pid>>8) & 0x1F
+	if pusi {
+		pkt[1] |= 0x40
+	}
+	pkt[2] = byte(pid)
+	pkt[3] = 0x10 | cc&0x0F
+	copy(pkt[4:], payload)
+	return pkt
+}
+
+// sectionPackets returns the transport stream packets carrying section on
+// pid, with continuity_counters starting at cc.
+func sectionPackets(pid uint16, cc byte, section []byte) [][]byte {
+	payload := append([]byte{0x00}, section...) // pointer_field
+	var pkts [][]byte
+	for i := 0; len(payload) > 0; i++ {
+		n := min(len(payload), 184)
+		pkts = append(pkts, tsPacket(pid, i == 0, cc+byte(i), payload[:n]))
+		payload = payload[n:]
+	}
+	return pkts
+}
+
+// psiSection returns a long-form PSI section containing body. The CRC_32 is
+// left empty as the demuxer does not verify it.
+func psiSection(tableID byte, body []byte) []byte {
+	n := len(body) + 5 + 4
+	b := []byte{tableID, 0xB0 | byte(n>>8), byte(n), 0x00, 0x01, 0xC1, 0x00, 0x00}
+	b = append(b, body...)
+	return append(b, 0x00, 0x00, 0x00, 0x00)
+}
+
+// patPMTPackets returns a PAT and a PMT carrying an SCTE 35 stream with
+// esInfo describing it.
+func patPMTPackets(streamType byte, esInfo []byte) [][]byte {
+	pat := psiSection(0x00, []byte{0x00, 0x01, 0xE0 | pmtPID>>8, pmtPID & 0xFF})
+	pmt := psiSection(0x02, append([]byte{
+		0xE1, 0x01, // PCR_PID
+		0xF0, 0x00, // program_info_length
+		0x1B, 0xE1, 0x01, 0xF0, 0x00, // AVC video
+		streamType, 0xE0 | scte35PID>>8, scte35PID & 0xFF, 0xF0, byte(len(esInfo)),
+	}, esInfo...))
+	return append(sectionPackets(0x0000, 0, pat), sectionPackets(pmtPID, 0, pmt)...)
+}
+
+// pcrPacket returns an adaptation field only transport stream packet carrying
+// a program_clock_reference with the given base.
+func pcrPacket(pid uint16, pcr uint64) []byte {
+	pkt := tsPacket(pid, false, 0, nil)
+	pkt[3] = 0x20 // adaptation_field_control
+	pkt[4] = 183  // adaptation_field_length
+	pkt[5] = 0x10 // PCR_flag
+	pkt[6] = byte(pcr >> 25)
+	pkt[7] = byte(pcr >> 17)
+	pkt[8] = byte(pcr >> 9)
+	pkt[9] = byte(pcr >> 1)
+	pkt[10] = byte(pcr<<7) | 0x7E
+	pkt[11] = 0x00
+	return pkt
+}
+
+// pesPacket returns a transport stream packet starting a PES packet with the
+// given PTS.
+func pesPacket(pid uint16, cc byte, pts uint64) []byte {
+	return tsPacket(pid, true, cc, []byte{
+		0x00, 0x00, 0x01, 0xE0, 0x00, 0x00, // start code, stream_id, PES_packet_length
+		0x80, 0x80, 0x05, // PTS only
+		0x21 | byte(pts>>29)&0x0E,
+		byte(pts >> 22),
+		byte(pts>>14) | 0x01,
+		byte(pts >> 7),
+		byte(pts<<1) | 0x01,
+	})
+}
+
+// join concatenates packets.
+func join(pkts ...[][]byte) []byte {
+	var b []byte
+	for _, p := range pkts {
+		b = append(b, bytes.Join(p, nil)...)
+	}
+	return b
+}
+
+// longSignal returns a splice_info_section that spans multiple packets.
+func longSignal(t *testing.T, eventID uint32) (*scte35.SpliceInfoSection, []byte) {
+	t.Helper()
+	sis := &scte35.SpliceInfoSection{
+		SpliceCommand: scte35.NewTimeSignal(0x100),
+		Tier:          4095,
+		SAPType:       3,
+	}
+	for i := 0; i < 8; i++ {
+		sis.SpliceDescriptors = append(sis.SpliceDescriptors, &scte35.SegmentationDescriptor{
+			SegmentationEventID: eventID + uint32(i),
+			SegmentationTypeID:  scte35.SegmentationTypeProviderAdStart,
+			SegmentationUPIDs: []scte35.SegmentationUPID{
+				scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeURI, []byte("urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6")),
+			},
+			DeliveryRestrictions: &scte35.DeliveryRestrictions{NoRegionalBlackoutFlag: true, ArchiveAllowedFlag: true, DeviceRestrictions: 3},
+		})
+	}
+	b, err := sis.Encode()
+	require.NoError(t, err)
+	require.Greater(t, len(b), 183+184)
+	return sis, b
+}
+
+// tsCueResult is the JSON representation of a cue printed by `ts`.
+type tsCueResult struct {
+	Packet            int                       `json:"packet"`
+	PID               uint16                    `json:"pid"`
+	PCR               *uint64                   `json:"pcr"`
+	PTS               *uint64                   `json:"pts"`
+	Error             string                    `json:"error"`
+	SpliceInfoSection *scte35.SpliceInfoSection `json:"spliceInfoSection"`
+}
+
+// readCueResults parses the JSON cues printed by `ts` and `listen`.
+func readCueResults(t *testing.T, out string) []tsCueResult {
+	t.Helper()
+	var res []tsCueResult
+	dec := json.NewDecoder(strings.NewReader(out))
+	for dec.More() {
+		var r tsCueResult
+		require.NoError(t, dec.Decode(&r))
+		res = append(res, r)
+	}
+	return res
+}
+
+func TestTS(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	short, err := scte35.DecodeBase64("/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==")
+	require.NoError(t, err)
+	shortBytes, err := short.Encode()
+	require.NoError(t, err)
+
+	long, longBytes := longSignal(t, 1)
+	_, otherBytes := longSignal(t, 100)
+	require.NotEqual(t, longBytes, otherBytes)
+
+	psi := patPMTPackets(0x86, nil)
+	longPkts := sectionPackets(scte35PID, 0, longBytes)
+	otherPkts := sectionPackets(scte35PID, 0, otherBytes)
+	require.Len(t, longPkts, 3)
+
+	type expected struct {
+		packet int
+		sis    *scte35.SpliceInfoSection
+	}
+
+	cases := map[string]struct {
+		stream   []byte
+		expected []expected
+	}{
+		"Single Packet": {
+			stream:   join(psi, sectionPackets(scte35PID, 0, shortBytes)),
+			expected: []expected{{packet: 2, sis: short}},
+		},
+		"Multiple Packets": {
+			stream: join(psi, longPkts, sectionPackets(scte35PID, 3, shortBytes)),
+			expected: []expected{
+				{packet: 4, sis: long},
+				{packet: 5, sis: short},
+			},
+		},
+		"Registration Descriptor": {
+			stream:   join(patPMTPackets(0x06, []byte{0x05, 0x04, 'C', 'U', 'E', 'I'}), sectionPackets(scte35PID, 0, shortBytes)),
+			expected: []expected{{packet: 2, sis: short}},
+		},
+		"Unknown PID": {
+			stream: join(psi, sectionPackets(scte35PID+1, 0, shortBytes)),
+		},
+		"No PMT": {
+			stream: join(sectionPackets(scte35PID, 0, shortBytes)),
+		},
+		"Duplicate Packet": {
+			stream: join(psi, [][]byte{longPkts[0], longPkts[1], longPkts[1], longPkts[2]}),
+			expected: []expected{
+				{packet: 5, sis: long},
+			},
+		},
+		"Lost Packet": {
+			// the remaining packets of other follow the first packet of long
+			stream: join(psi, [][]byte{
+				longPkts[0],
+				tsPacket(scte35PID, false, 2, otherPkts[1][4:]),
+				tsPacket(scte35PID, false, 3, otherPkts[2][4:]),
+			}, sectionPackets(scte35PID, 4, shortBytes)),
+			expected: []expected{
+				{packet: 5, sis: short},
+			},
+		},
+		"Resync": {
+			// sync bytes that are not followed by another at the next
+			// packet boundary are skipped
+			stream:   join([][]byte{{0x47, 0x00, 0x47, 0x10, 0x00}}, psi, sectionPackets(scte35PID, 0, shortBytes)),
+			expected: []expected{{packet: 2, sis: short}},
+		},
+		"Truncated": {
+			stream: join(psi, longPkts)[:188*2+300],
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, bytes.NewReader(c.stream), "ts", "--out", "json")
+			require.NoError(t, err)
+
+			res := readCueResults(t, out)
+			require.Len(t, res, len(c.expected))
+			for i, e := range c.expected {
+				require.Empty(t, res[i].Error)
+				require.Equal(t, e.packet, res[i].Packet)
+				require.Equal(t, uint16(scte35PID), res[i].PID)
+				require.Empty(t, scte35.Diff(e.sis, res[i].SpliceInfoSection))
+			}
+		})
+	}
+}
+
+func TestTS_MPTS(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	b, err := base64.StdEncoding.DecodeString("/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==")
+	require.NoError(t, err)
+
+	// program 1: PMT 0x100, PCR and video 0x101, SCTE 35 0x1F4
+	// program 2: PMT 0x200, PCR 0x202, video 0x201, SCTE 35 0x2F4
+	pat := psiSection(0x00, []byte{0x00, 0x01, 0xE1, 0x00, 0x00, 0x02, 0xE2, 0x00})
+	pmt1 := psiSection(0x02, []byte{
+		0xE1, 0x01, 0xF0, 0x00,
+		0x1B, 0xE1, 0x01, 0xF0, 0x00,
+		0x86, 0xE1, 0xF4, 0xF0, 0x00,
+	})
+	pmt2 := psiSection(0x02, []byte{
+		0xE2, 0x02, 0xF0, 0x00,
+		0x1B, 0xE2, 0x01, 0xF0, 0x00,
+		0x86, 0xE2, 0xF4, 0xF0, 0x00,
+	})
+	pmt2[4] = 0x02 // program_number
+
+	stream := join(
+		sectionPackets(0x0000, 0, pat),
+		sectionPackets(0x100, 0, pmt1),
+		sectionPackets(0x200, 0, pmt2),
+		[][]byte{
+			pcrPacket(0x101, 1000),
+			pesPacket(0x101, 0, 2000),
+			pcrPacket(0x202, 5000),
+			pesPacket(0x201, 0, 6000),
+		},
+		sectionPackets(0x1F4, 0, b),
+		sectionPackets(0x2F4, 0, b),
+		[][]byte{pcrPacket(0x101, 1001)},
+		sectionPackets(0x2F4, 1, b),
+	)
+
+	out, err := execute(t, bytes.NewReader(stream), "ts", "--out", "json")
+	require.NoError(t, err)
+
+	type cue struct {
+		pid      uint16
+		pcr, pts uint64
+	}
+	var actual []cue
+	for _, res := range readCueResults(t, out) {
+		require.Empty(t, res.Error)
+		require.NotNil(t, res.PCR)
+		require.NotNil(t, res.PTS)
+		actual = append(actual, cue{pid: res.PID, pcr: *res.PCR, pts: *res.PTS})
+	}
+	require.Equal(t, []cue{
+		{pid: 0x1F4, pcr: 1000, pts: 2000},
+		{pid: 0x2F4, pcr: 5000, pts: 6000},
+		{pid: 0x2F4, pcr: 5000, pts: 6000},
+	}, actual)
+}
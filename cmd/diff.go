@@ -0,0 +1,185 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// diffCommand returns the command for `scte35 diff`
+func diffCommand() *cobra.Command {
+	var format string
+	var crc bool
+	cmd := &cobra.Command{
+		Use:   "diff <signal> <signal>",
+		Short: "Compare two splice_info_sections",
+		Long: "Compare two splice_info_sections, each provided as base-64,\n" +
+			"hexadecimal, JSON or XML (see convert).\n\n" +
+			"The text output shows the tables of both signals, marking lines only in\n" +
+			"the first signal with '-' and lines only in the second with '+'. The\n" +
+			"json and xml outputs list each field-level difference. Decoding\n" +
+			"artifacts (alignment_stuffing and the CRC_32) are ignored unless --crc\n" +
+			"is set. An error is returned if the signals differ.",
+		Args:         cobra.ExactArgs(2),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			a, err := parseSignal(args[0])
+			if err != nil {
+				return fmt.Errorf("first signal: %w", err)
+			}
+			b, err := parseSignal(args[1])
+			if err != nil {
+				return fmt.Errorf("second signal: %w", err)
+			}
+
+			diffs := scte35.Diff(a, b)
+			if crc {
+				diffs = append(diffs, scte35.DiffEncoding(a, b)...)
+			}
+			if err := printDiff(c.OutOrStdout(), format, a, b, diffs, crc); err != nil {
+				return err
+			}
+			if len(diffs) > 0 {
+				return fmt.Errorf("%d differences found", len(diffs))
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	cmd.Flags().BoolVar(&crc, "crc", false, "include alignment_stuffing and CRC_32 differences")
+	return cmd
+}
+
+// differenceOutput is the JSON and XML representation of a
+// scte35.Difference.
+type differenceOutput struct {
+	Path string `xml:"path,attr" json:"path"`
+	A    string `xml:"a,attr" json:"a"`
+	B    string `xml:"b,attr" json:"b"`
+}
+
+// diffOutput is the JSON and XML representation of the differences between
+// two signals.
+type diffOutput struct {
+	XMLName     xml.Name           `xml:"Diff" json:"-"`
+	Differences []differenceOutput `xml:"Difference" json:"differences"`
+}
+
+// parseSignal decodes a signal in any representation supported by convert.
+// A signal with an invalid CRC_32 is returned without error so it can be
+// compared.
+func parseSignal(s string) (*scte35.SpliceInfoSection, error) {
+	sis, err := scte35.ReadSignal([]byte(s), scte35.RepresentationAuto)
+	if errors.Is(err, scte35.ErrCRC32Invalid) {
+		err = nil
+	}
+	return sis, err
+}
+
+// printDiff prints the differences between a and b in the requested format.
+func printDiff(w io.Writer, format string, a, b *scte35.SpliceInfoSection, diffs []scte35.Difference, crc bool) error {
+	switch format {
+	case "json", "xml":
+		out := diffOutput{Differences: make([]differenceOutput, len(diffs))}
+		for i, d := range diffs {
+			va, vb := d.ValueStrings()
+			out.Differences[i] = differenceOutput{Path: d.Path, A: va, B: vb}
+		}
+		var buf []byte
+		if format == "json" {
+			buf, _ = json.MarshalIndent(&out, "", "\t")
+		} else {
+			buf, _ = xml.MarshalIndent(&out, "", "\t")
+		}
+		_, err := fmt.Fprintf(w, "%s\n", buf)
+		return err
+	default:
+		for _, l := range diffLines(tableLines(a), tableLines(b)) {
+			if _, err := fmt.Fprintf(w, "%c %s\n", l.op, l.text); err != nil {
+				return err
+			}
+		}
+		if crc {
+			for _, d := range scte35.DiffEncoding(a, b) {
+				va, vb := d.ValueStrings()
+				if _, err := fmt.Fprintf(w, "- %s: %s\n+ %s: %s\n", d.Path, va, d.Path, vb); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+}
+
+// tableLines returns the lines of the SpliceInfoSection's table.
+func tableLines(sis *scte35.SpliceInfoSection) []string {
+	return strings.Split(strings.TrimRight(sis.Table("", "\t"), "\n"), "\n")
+}
+
+// diffLine is a line of a line-based diff.
+type diffLine struct {
+	// op is ' ' for lines in both inputs, '-' for lines only in the first
+	// and '+' for lines only in the second.
+	op   rune
+	text string
+}
+
+// diffLines returns a line-based diff of a and b, using the longest common
+// subsequence of lines.
+func diffLines(a, b []string) []diffLine {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and
+	// b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	lines := make([]diffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			lines = append(lines, diffLine{op: ' ', text: a[i]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			lines = append(lines, diffLine{op: '-', text: a[i]})
+			i++
+		default:
+			lines = append(lines, diffLine{op: '+', text: b[j]})
+			j++
+		}
+	}
+	return lines
+}
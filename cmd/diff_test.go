@@ -0,0 +1,157 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/cmd"
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffLines(t *testing.T) {
+	cases := map[string]struct {
+		a        string
+		b        string
+		expected []string
+	}{
+		"Empty": {},
+		"Equal": {
+			a:        "a b c",
+			b:        "a b c",
+			expected: []string{"  a", "  b", "  c"},
+		},
+		"Added": {
+			b:        "a b",
+			expected: []string{"+ a", "+ b"},
+		},
+		"Removed": {
+			a:        "a b",
+			expected: []string{"- a", "- b"},
+		},
+		"Changed": {
+			a:        "a b c",
+			b:        "a x c",
+			expected: []string{"  a", "- b", "+ x", "  c"},
+		},
+		"Inserted": {
+			a:        "a c",
+			b:        "a b c d",
+			expected: []string{"  a", "+ b", "  c", "+ d"},
+		},
+		"Deleted": {
+			a:        "a b c d",
+			b:        "b d",
+			expected: []string{"- a", "  b", "- c", "  d"},
+		},
+		"Moved": {
+			// the longest common subsequence is kept
+			a:        "a b c d",
+			b:        "b c d a",
+			expected: []string{"- a", "  b", "  c", "  d", "+ a"},
+		},
+		"Repeated": {
+			a:        "x a x b",
+			b:        "a x b x",
+			expected: []string{"- x", "  a", "  x", "  b", "+ x"},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			require.Equal(t, c.expected, cmd.DiffLines(strings.Fields(c.a), strings.Fields(c.b)))
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+
+	sis, err := scte35.DecodeBase64(signal)
+	require.NoError(t, err)
+	js, err := json.Marshal(sis)
+	require.NoError(t, err)
+
+	sis.Tier = 0x100
+	tier := "0x" + sis.Hex()
+
+	cases := map[string]struct {
+		args     []string
+		expected []string // substrings of the output
+		err      string
+	}{
+		"Equal": {
+			args:     []string{"diff", signal, string(js)},
+			expected: []string{"  tier: 4095\n"},
+		},
+		"Equal CRC": {
+			args:     []string{"diff", "--crc", signal, signal},
+			expected: []string{"  tier: 4095\n"},
+		},
+		"Text": {
+			args:     []string{"diff", signal, tier},
+			expected: []string{"  cw_index: 255\n- tier: 4095\n+ tier: 256\n  splice_command_length: 5\n"},
+			err:      "1 differences found",
+		},
+		"Text CRC": {
+			args:     []string{"diff", "--crc", signal, tier},
+			expected: []string{"- tier: 4095\n+ tier: 256\n", "- crc32: 0x9AC9D17E\n+ crc32: "},
+			err:      "2 differences found",
+		},
+		"JSON": {
+			args:     []string{"diff", "--out", "json", signal, tier},
+			expected: []string{`"path": "tier",` + "\n\t\t\t" + `"a": "4095",` + "\n\t\t\t" + `"b": "256"`},
+			err:      "1 differences found",
+		},
+		"XML": {
+			args:     []string{"diff", "--out", "xml", signal, tier},
+			expected: []string{`<Diff>` + "\n\t" + `<Difference path="tier" a="4095" b="256"></Difference>` + "\n</Diff>\n"},
+			err:      "1 differences found",
+		},
+		"Invalid First Signal": {
+			args: []string{"diff", "bogus", signal},
+			err:  "first signal: ",
+		},
+		"Invalid Second Signal": {
+			args: []string{"diff", signal, "0xfc30"},
+			err:  "second signal: ",
+		},
+		"Missing Signal": {
+			args: []string{"diff", signal},
+			err:  "accepts 2 arg(s), received 1",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, nil, c.args...)
+			if c.err == "" {
+				require.NoError(t, err)
+				require.NotRegexp(t, `(?m)^[-+] `, out)
+			} else {
+				require.ErrorContains(t, err, c.err)
+			}
+			for _, e := range c.expected {
+				require.Contains(t, out, e)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+// DiffLines exposes diffLines to tests, formatting each line as it is
+// printed by `scte35 diff`.
+func DiffLines(a, b []string) []string {
+	var lines []string
+	for _, l := range diffLines(a, b) {
+		lines = append(lines, string(l.op)+" "+l.text)
+	}
+	return lines
+}
+
+// ParseSegmentationType exposes parseSegmentationType to tests.
+var ParseSegmentationType = parseSegmentationType
+
+// ParseUPID exposes parseUPID to tests.
+var ParseUPID = parseUPID
+
+// ColorEnabled exposes colorEnabled to tests.
+var ColorEnabled = colorEnabled
@@ -0,0 +1,366 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// hlsDurationTolerance is the maximum difference between a tag's duration
+// and the signal's duration before a warning is reported.
+const hlsDurationTolerance = 0.01 // seconds
+
+// hlsCommand returns the command for `scte35 hls`
+func hlsCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "hls [filename]",
+		Short: "Audit the SCTE 35 tags in an HLS media playlist",
+		Long: "List the SCTE 35 bearing tags (EXT-X-DATERANGE, EXT-X-CUE-OUT,\n" +
+			"EXT-X-CUE-OUT-CONT, EXT-X-CUE-IN, EXT-X-SCTE35 and EXT-OATCLS-SCTE35) in\n" +
+			"an HLS media playlist file (or stdin) with the segment sequence number,\n" +
+			"media time and decoded signal, followed by any consistency warnings:\n\n" +
+			"  - CUE-OUT without a matching CUE-IN (and vice versa)\n" +
+			"  - DATERANGE SCTE35-OUT without a matching SCTE35-IN\n" +
+			"  - tag DURATION not matching the signal's duration\n" +
+			"  - splice or segmentation event IDs reused by another cue (the same\n" +
+			"    signal, or any signal before the same segment, is not a reuse)",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("requires at most one playlist file")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			in := c.InOrStdin()
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				in = f
+			}
+
+			audit, err := auditPlaylist(in)
+			if err != nil {
+				return err
+			}
+			if err := audit.print(c.OutOrStdout(), format); err != nil {
+				return err
+			}
+
+			issues := len(audit.Warnings)
+			for _, c := range audit.Cues {
+				if c.Error != "" {
+					issues++
+				}
+			}
+			if issues > 0 {
+				return fmt.Errorf("%d issues found", issues)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	return cmd
+}
+
+// hlsAudit is the result of auditing a playlist.
+type hlsAudit struct {
+	XMLName  xml.Name `xml:"Playlist" json:"-"`
+	Cues     []hlsCue `xml:"Cue" json:"cues"`
+	Warnings []string `xml:"Warning" json:"warnings,omitempty"`
+}
+
+// hlsCue is an SCTE 35 bearing tag.
+type hlsCue struct {
+	Line              int                       `xml:"line,attr" json:"line"`
+	Sequence          int                       `xml:"sequence,attr" json:"sequence"`
+	Time              float64                   `xml:"time,attr" json:"time"`
+	Tag               string                    `xml:"tag,attr" json:"tag"`
+	Error             string                    `xml:"error,attr,omitempty" json:"error,omitempty"`
+	SpliceInfoSection *scte35.SpliceInfoSection `xml:"SpliceInfoSection,omitempty" json:"spliceInfoSection,omitempty"`
+	duration          *float64                  // DURATION declared by the tag
+	continuation      bool                      // repeats an earlier cue
+}
+
+// hlsEvent records the cue in which an event ID was first seen.
+type hlsEvent struct {
+	line     int
+	sequence int
+	signal   []byte
+}
+
+// auditPlaylist reads an HLS media playlist, returning the SCTE 35 bearing
+// tags and any consistency warnings.
+func auditPlaylist(r io.Reader) (*hlsAudit, error) {
+	audit := &hlsAudit{}
+	warnf := func(format string, a ...interface{}) {
+		audit.Warnings = append(audit.Warnings, fmt.Sprintf(format, a...))
+	}
+
+	var (
+		sequence  int
+		mediaTime float64                 // start of the next segment
+		extinf    float64                 // duration of the next segment
+		cueOut    *hlsCue                 // open EXT-X-CUE-OUT
+		dateRange = map[string]int{}      // open SCTE35-OUT DATERANGE IDs -> line
+		eventIDs  = map[uint32]hlsEvent{} // event IDs -> cue first seen
+	)
+
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if n == 1 && line != "#EXTM3U" {
+			warnf("line 1: playlist does not start with #EXTM3U")
+		}
+
+		name, value, _ := strings.Cut(line, ":")
+		if !strings.HasPrefix(line, "#") {
+			// segment URI
+			if line != "" {
+				sequence++
+				mediaTime += extinf
+				extinf = 0
+			}
+			continue
+		}
+
+		cue := hlsCue{Line: n, Sequence: sequence, Time: mediaTime, Tag: line}
+		var signal string
+		switch name {
+		case "#EXT-X-MEDIA-SEQUENCE":
+			sequence, _ = strconv.Atoi(value)
+			continue
+		case "#EXTINF":
+			d, _, _ := strings.Cut(value, ",")
+			f, err := strconv.ParseFloat(d, 64)
+			if err != nil {
+				warnf("line %d: invalid EXTINF duration %q", n, d)
+			}
+			extinf = f
+			continue
+		case "#EXT-X-DATERANGE":
+			attrs := parseAttributes(value)
+			cue.duration = attributeFloat(attrs, "DURATION", "PLANNED-DURATION")
+			id := attrs["ID"]
+			switch {
+			case attrs["SCTE35-OUT"] != "":
+				signal = attrs["SCTE35-OUT"]
+				if _, ok := dateRange[id]; ok {
+					warnf("line %d: DATERANGE %q SCTE35-OUT repeated before SCTE35-IN", n, id)
+				}
+				dateRange[id] = n
+			case attrs["SCTE35-IN"] != "":
+				signal = attrs["SCTE35-IN"]
+				if _, ok := dateRange[id]; !ok {
+					warnf("line %d: DATERANGE %q SCTE35-IN without matching SCTE35-OUT", n, id)
+				}
+				delete(dateRange, id)
+			case attrs["SCTE35-CMD"] != "":
+				signal = attrs["SCTE35-CMD"]
+			default:
+				continue
+			}
+		case "#EXT-X-CUE-OUT":
+			attrs := parseAttributes(value)
+			cue.duration = attributeFloat(attrs, "DURATION")
+			if cue.duration == nil {
+				if f, err := strconv.ParseFloat(value, 64); err == nil {
+					cue.duration = &f
+				}
+			}
+			signal = attrs["SCTE35"]
+			if cueOut != nil {
+				warnf("line %d: CUE-OUT while CUE-OUT from line %d has no matching CUE-IN", n, cueOut.Line)
+			}
+			cueOut = &cue
+		case "#EXT-X-CUE-OUT-CONT":
+			signal = parseAttributes(value)["SCTE35"]
+			cue.continuation = true
+			if cueOut == nil {
+				warnf("line %d: CUE-OUT-CONT without CUE-OUT", n)
+			}
+		case "#EXT-X-CUE-IN":
+			signal = parseAttributes(value)["SCTE35"]
+			if cueOut == nil {
+				warnf("line %d: CUE-IN without matching CUE-OUT", n)
+			}
+			cueOut = nil
+		case "#EXT-X-SCTE35":
+			attrs := parseAttributes(value)
+			signal = attrs["CUE"]
+			cue.continuation = attrs["CUE-OUT"] == "CONT"
+		case "#EXT-OATCLS-SCTE35":
+			signal = value
+		default:
+			continue
+		}
+
+		if signal != "" {
+			sis, err := scte35.ReadSignal([]byte(signal), scte35.RepresentationAuto)
+			if err != nil {
+				cue.Error = err.Error()
+			}
+			if sis.SpliceCommand != nil {
+				cue.SpliceInfoSection = sis
+			}
+		}
+		if sis := cue.SpliceInfoSection; sis != nil && cue.Error == "" {
+			if cue.duration != nil && sis.Duration() > 0 {
+				if d := sis.Duration().Seconds(); math.Abs(d-*cue.duration) > hlsDurationTolerance {
+					warnf("line %d: tag DURATION %gs does not match signal duration %gs", n, *cue.duration, d)
+				}
+			}
+			if !cue.continuation {
+				b, _ := scte35.SignalBytes([]byte(signal), scte35.RepresentationAuto)
+				for _, id := range startEventIDs(sis) {
+					first, ok := eventIDs[id]
+					switch {
+					case !ok:
+						eventIDs[id] = hlsEvent{line: n, sequence: sequence, signal: b}
+					case first.sequence == sequence, bytes.Equal(first.signal, b):
+						// the same cue carried by another tag (ie, both
+						// EXT-X-CUE-OUT and EXT-X-DATERANGE)
+					default:
+						warnf("line %d: event ID %d reused (first seen on line %d)", n, id, first.line)
+					}
+				}
+			}
+		}
+		audit.Cues = append(audit.Cues, cue)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if cueOut != nil {
+		warnf("line %d: CUE-OUT without matching CUE-IN", cueOut.Line)
+	}
+	ids := make([]string, 0, len(dateRange))
+	for id := range dateRange {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return dateRange[ids[i]] < dateRange[ids[j]] })
+	for _, id := range ids {
+		warnf("line %d: DATERANGE %q SCTE35-OUT without matching SCTE35-IN", dateRange[id], id)
+	}
+	return audit, nil
+}
+
+// print writes the audit in the requested format.
+func (a *hlsAudit) print(w io.Writer, format string) error {
+	var b []byte
+	switch format {
+	case "json":
+		b, _ = json.MarshalIndent(a, "", "\t")
+	case "xml":
+		b, _ = xml.MarshalIndent(a, "", "\t")
+	default:
+		var sb strings.Builder
+		for _, c := range a.Cues {
+			_, _ = fmt.Fprintf(&sb, "line: %d, sequence: %d, time: %s\n%s\n", c.Line, c.Sequence, time.Duration(c.Time*float64(time.Second)), c.Tag)
+			if c.SpliceInfoSection != nil {
+				sb.WriteString(c.SpliceInfoSection.Table("", "\t"))
+			}
+			if c.Error != "" {
+				_, _ = fmt.Fprintf(&sb, "Error: %s\n", c.Error)
+			}
+			sb.WriteString("\n")
+		}
+		if len(a.Warnings) > 0 {
+			sb.WriteString("Warnings:\n")
+			for _, warning := range a.Warnings {
+				_, _ = fmt.Fprintf(&sb, "\t%s\n", warning)
+			}
+		}
+		b = []byte(strings.TrimSuffix(sb.String(), "\n"))
+	}
+	_, err := fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+// startEventIDs returns the splice_event_id or segmentation_event_ids of any
+// out of network splice_insert or Start segmentation_descriptors in sis.
+func startEventIDs(sis *scte35.SpliceInfoSection) []uint32 {
+	var ids []uint32
+	if cmd, ok := sis.SpliceCommand.(*scte35.SpliceInsert); ok && cmd.OutOfNetworkIndicator && !cmd.SpliceEventCancelIndicator {
+		ids = append(ids, cmd.SpliceEventID)
+	}
+	for _, d := range sis.SpliceDescriptors {
+		if sd, ok := d.(*scte35.SegmentationDescriptor); ok && !sd.SegmentationEventCancelIndicator && strings.HasSuffix(sd.Name(), " Start") {
+			ids = append(ids, sd.SegmentationEventID)
+		}
+	}
+	return ids
+}
+
+// parseAttributes parses an HLS attribute-list (ie, ID="1",DURATION=30.0),
+// removing quotes from quoted-string values.
+func parseAttributes(s string) map[string]string {
+	attrs := map[string]string{}
+	for s != "" {
+		name, rest, ok := strings.Cut(s, "=")
+		if !ok {
+			break
+		}
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			value = rest[1 : end+1]
+			rest = rest[min(end+2, len(rest)):]
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+			rest = "," + rest
+		}
+		attrs[strings.ToUpper(strings.TrimSpace(name))] = value
+		s = strings.TrimPrefix(rest, ",")
+	}
+	return attrs
+}
+
+// attributeFloat returns the value of the first of the named attributes that
+// is present as a decimal-floating-point.
+func attributeFloat(attrs map[string]string, names ...string) *float64 {
+	for _, name := range names {
+		if v, ok := attrs[name]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				return &f
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHLS(t *testing.T) {
+	// Sample 14.2 splice_insert (splice_event_id 1207959695, 60.293567s)
+	const out = "/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo="
+
+	sis, err := scte35.DecodeBase64(out)
+	require.NoError(t, err)
+	outHex := "0x" + sis.Hex()
+	outUpperHex := "0X" + strings.ToUpper(sis.Hex())
+
+	// a different splice_insert with the same splice_event_id
+	sis.SpliceCommand.(*scte35.SpliceInsert).Program.SpliceTime.PTSTime = ptr(uint64(0x100))
+	reused := sis.Base64()
+
+	type cue struct {
+		Line     int     `json:"line"`
+		Sequence int     `json:"sequence"`
+		Time     float64 `json:"time"`
+		Tag      string  `json:"tag"`
+		Error    string  `json:"error"`
+	}
+
+	cases := map[string]struct {
+		playlist string
+		cues     []cue
+		warnings []string
+		err      string
+	}{
+		"Matched": {
+			playlist: "#EXTM3U\n" +
+				"#EXT-X-MEDIA-SEQUENCE:10\n" +
+				"#EXTINF:6.0,\n" +
+				"a.ts\n" +
+				`#EXT-X-DATERANGE:ID="1",START-DATE="2024-01-01T00:00:00Z",DURATION=60.293,SCTE35-OUT=` + outHex + "\n" +
+				"#EXT-X-CUE-OUT:60.293\n" +
+				"#EXT-OATCLS-SCTE35:" + out + "\n" +
+				"#EXTINF:6.0,\n" +
+				"b.ts\n" +
+				"#EXT-X-CUE-OUT-CONT:ElapsedTime=6.0,Duration=60.293,SCTE35=" + out + "\n" +
+				"#EXTINF:6.0,\n" +
+				"c.ts\n" +
+				"#EXT-X-CUE-IN\n" +
+				`#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outHex + "\n" +
+				"#EXTINF:6.0,\n" +
+				"d.ts\n" +
+				"#EXT-OATCLS-SCTE35:" + out + "\n",
+			cues: []cue{
+				{Line: 5, Sequence: 11, Time: 6, Tag: `#EXT-X-DATERANGE:ID="1",START-DATE="2024-01-01T00:00:00Z",DURATION=60.293,SCTE35-OUT=` + outHex},
+				{Line: 6, Sequence: 11, Time: 6, Tag: "#EXT-X-CUE-OUT:60.293"},
+				{Line: 7, Sequence: 11, Time: 6, Tag: "#EXT-OATCLS-SCTE35:" + out},
+				{Line: 10, Sequence: 12, Time: 12, Tag: "#EXT-X-CUE-OUT-CONT:ElapsedTime=6.0,Duration=60.293,SCTE35=" + out},
+				{Line: 13, Sequence: 13, Time: 18, Tag: "#EXT-X-CUE-IN"},
+				{Line: 14, Sequence: 13, Time: 18, Tag: `#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outHex},
+				// the same signal repeated later is not a reuse
+				{Line: 17, Sequence: 14, Time: 24, Tag: "#EXT-OATCLS-SCTE35:" + out},
+			},
+		},
+		"Upper Case Hex Prefix": {
+			playlist: "#EXTM3U\n" +
+				`#EXT-X-DATERANGE:ID="1",START-DATE="2024-01-01T00:00:00Z",DURATION=60.293,SCTE35-OUT=` + outUpperHex + "\n" +
+				"#EXT-X-CUE-OUT:DURATION=60.293,SCTE35=" + outUpperHex + "\n" +
+				"#EXTINF:6.0,\n" +
+				"a.ts\n" +
+				"#EXT-X-CUE-IN\n" +
+				`#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outUpperHex + "\n",
+			cues: []cue{
+				{Line: 2, Tag: `#EXT-X-DATERANGE:ID="1",START-DATE="2024-01-01T00:00:00Z",DURATION=60.293,SCTE35-OUT=` + outUpperHex},
+				{Line: 3, Tag: "#EXT-X-CUE-OUT:DURATION=60.293,SCTE35=" + outUpperHex},
+				{Line: 6, Sequence: 1, Time: 6, Tag: "#EXT-X-CUE-IN"},
+				{Line: 7, Sequence: 1, Time: 6, Tag: `#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outUpperHex},
+			},
+		},
+		"Missing EXTM3U": {
+			playlist: "#EXTINF:6.0,\na.ts\n",
+			warnings: []string{"line 1: playlist does not start with #EXTM3U"},
+			err:      "1 issues found",
+		},
+		"Unmatched CUE-OUT": {
+			playlist: "#EXTM3U\n#EXT-X-CUE-OUT:30\n#EXTINF:6.0,\na.ts\n#EXT-X-CUE-OUT:30\n",
+			cues: []cue{
+				{Line: 2, Tag: "#EXT-X-CUE-OUT:30"},
+				{Line: 5, Sequence: 1, Time: 6, Tag: "#EXT-X-CUE-OUT:30"},
+			},
+			warnings: []string{
+				"line 5: CUE-OUT while CUE-OUT from line 2 has no matching CUE-IN",
+				"line 5: CUE-OUT without matching CUE-IN",
+			},
+			err: "2 issues found",
+		},
+		"Unmatched CUE-IN": {
+			playlist: "#EXTM3U\n#EXT-X-CUE-OUT-CONT:ElapsedTime=6.0\n#EXT-X-CUE-IN\n",
+			cues: []cue{
+				{Line: 2, Tag: "#EXT-X-CUE-OUT-CONT:ElapsedTime=6.0"},
+				{Line: 3, Tag: "#EXT-X-CUE-IN"},
+			},
+			warnings: []string{
+				"line 2: CUE-OUT-CONT without CUE-OUT",
+				"line 3: CUE-IN without matching CUE-OUT",
+			},
+			err: "2 issues found",
+		},
+		"Unmatched DATERANGE": {
+			playlist: "#EXTM3U\n" +
+				`#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outHex + "\n" +
+				`#EXT-X-DATERANGE:ID="2",SCTE35-OUT=` + outHex + "\n",
+			cues: []cue{
+				{Line: 2, Tag: `#EXT-X-DATERANGE:ID="1",SCTE35-IN=` + outHex},
+				{Line: 3, Tag: `#EXT-X-DATERANGE:ID="2",SCTE35-OUT=` + outHex},
+			},
+			warnings: []string{
+				`line 2: DATERANGE "1" SCTE35-IN without matching SCTE35-OUT`,
+				`line 3: DATERANGE "2" SCTE35-OUT without matching SCTE35-IN`,
+			},
+			err: "2 issues found",
+		},
+		"DATERANGE Duration Mismatch": {
+			playlist: "#EXTM3U\n" +
+				`#EXT-X-DATERANGE:ID="1",PLANNED-DURATION=30,SCTE35-CMD=` + out + "\n",
+			cues: []cue{
+				{Line: 2, Tag: `#EXT-X-DATERANGE:ID="1",PLANNED-DURATION=30,SCTE35-CMD=` + out},
+			},
+			warnings: []string{"line 2: tag DURATION 30s does not match signal duration 60.293566666s"},
+			err:      "1 issues found",
+		},
+		"Event ID Reused": {
+			playlist: "#EXTM3U\n" +
+				"#EXT-OATCLS-SCTE35:" + out + "\n" +
+				"#EXTINF:6.0,\n" +
+				"a.ts\n" +
+				"#EXT-OATCLS-SCTE35:" + reused + "\n",
+			cues: []cue{
+				{Line: 2, Tag: "#EXT-OATCLS-SCTE35:" + out},
+				{Line: 5, Sequence: 1, Time: 6, Tag: "#EXT-OATCLS-SCTE35:" + reused},
+			},
+			warnings: []string{"line 5: event ID 1207959695 reused (first seen on line 2)"},
+			err:      "1 issues found",
+		},
+		"Invalid Signal": {
+			playlist: "#EXTM3U\n#EXT-X-SCTE35:CUE=bogus\n",
+			cues: []cue{
+				{Line: 2, Tag: "#EXT-X-SCTE35:CUE=bogus", Error: scte35.ErrUnsupportedEncoding.Error()},
+			},
+			err: "1 issues found",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			stdout, err := execute(t, strings.NewReader(c.playlist), "hls", "--out", "json")
+			if c.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.EqualError(t, err, c.err)
+			}
+
+			var audit struct {
+				Cues     []cue    `json:"cues"`
+				Warnings []string `json:"warnings"`
+			}
+			require.NoError(t, json.Unmarshal([]byte(stdout), &audit))
+			require.Equal(t, c.cues, audit.Cues)
+			require.Equal(t, c.warnings, audit.Warnings)
+		})
+	}
+}
+
+// ptr returns a pointer to v.
+func ptr[T any](v T) *T {
+	return &v
+}
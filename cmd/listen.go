@@ -0,0 +1,202 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// udpMaxDatagramSize is the largest UDP payload.
+	udpMaxDatagramSize = 65535
+	// udpReadBufferSize is the requested socket receive buffer size, large
+	// enough to absorb bursts at typical transport stream bitrates.
+	udpReadBufferSize = 4 << 20
+)
+
+// listenCommand returns the command for `scte35 listen`
+func listenCommand() *cobra.Command {
+	var format, iface, output string
+	var count int
+	cmd := &cobra.Command{
+		Use:   "listen <udp://[group]:port>",
+		Short: "Decode the splice_info_sections in a UDP transport stream",
+		Long: "Receive an MPEG-2 transport stream over UDP and print each\n" +
+			"splice_info_section as it arrives, located as described for ts.\n\n" +
+			"The address may be a multicast group (joined on --interface, or the\n" +
+			"system default), a local unicast address or just a port (for example\n" +
+			"udp://:5000). A unicast port of 0 listens on a free port, which is\n" +
+			"reported on stderr. Datagrams may carry raw transport stream packets\n" +
+			"or RTP (RFC 3550) wrapped packets, which are detected automatically,\n" +
+			"so rtp:// is accepted as a synonym for udp://.\n\n" +
+			"Cues are printed as NDJSON by default, each with the time it was\n" +
+			"received and the sender's address. The command runs until interrupted\n" +
+			"or --count cues have been received.",
+		Args:         cobra.ExactArgs(1),
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "json" && format != "text" {
+				return fmt.Errorf("unsupported --out: %q", format)
+			}
+			addr, err := parseListenAddr(args[0])
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if output != "" && output != "-" {
+				f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0o644)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				w = f
+			}
+
+			conn, err := listenUDP(addr, iface)
+			if err != nil {
+				return err
+			}
+			_ = conn.SetReadBuffer(udpReadBufferSize)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				<-ctx.Done()
+				_ = conn.Close()
+			}()
+
+			_, _ = fmt.Fprintf(cmd.ErrOrStderr(), "Listening on %s\n", conn.LocalAddr())
+			err = receiveCues(conn, count, func(src net.Addr, cue tsCue) error {
+				return printListenCue(w, format, time.Now(), src, cue)
+			})
+			if ctx.Err() != nil && errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringVar(&iface, "interface", "", "network interface on which to join a multicast group")
+	cmd.Flags().IntVar(&count, "count", 0, "exit after receiving this many cues (0 for no limit)")
+	cmd.Flags().StringVarP(&output, "output", "o", "", "append to a file instead of stdout (- for stdout)")
+	cmd.PersistentFlags().StringVar(&format, "out", "json", "specify alternative output format (json, text)")
+	return cmd
+}
+
+// parseListenAddr parses a udp:// URL, returning the UDP address.
+func parseListenAddr(s string) (*net.UDPAddr, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "udp" && u.Scheme != "rtp" {
+		return nil, fmt.Errorf("unsupported scheme: %q (expected udp://)", u.Scheme)
+	}
+	if u.Port() == "" {
+		return nil, fmt.Errorf("missing port: %q", s)
+	}
+	// the "@" of VLC style udp://@group:port URLs is parsed as empty userinfo
+	addr, err := net.ResolveUDPAddr("udp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	if addr.Port == 0 && addr.IP.IsMulticast() {
+		return nil, fmt.Errorf("missing port: %q", s)
+	}
+	return addr, nil
+}
+
+// listenUDP listens on addr, joining the multicast group on the named
+// interface if addr is a multicast address.
+func listenUDP(addr *net.UDPAddr, iface string) (*net.UDPConn, error) {
+	if !addr.IP.IsMulticast() {
+		if iface != "" {
+			return nil, fmt.Errorf("--interface requires a multicast group")
+		}
+		return net.ListenUDP("udp", addr)
+	}
+	var ifi *net.Interface
+	if iface != "" {
+		var err error
+		if ifi, err = net.InterfaceByName(iface); err != nil {
+			return nil, err
+		}
+	}
+	return net.ListenMulticastUDP("udp", ifi, addr)
+}
+
+// receiveCues reads datagrams from conn, passing each cue to f until count
+// cues have been received (if count is positive) or a read fails.
+func receiveCues(conn *net.UDPConn, count int, f func(src net.Addr, cue tsCue) error) error {
+	d := newTSDemuxer()
+	buf := make([]byte, udpMaxDatagramSize)
+	received := 0
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			return err
+		}
+		for _, pkt := range datagramPackets(buf[:n]) {
+			for _, cue := range d.Write(pkt) {
+				if err := f(src, cue); err != nil {
+					return err
+				}
+				received++
+				if count > 0 && received >= count {
+					return nil
+				}
+			}
+		}
+	}
+}
+
+// listenCueOutput is the NDJSON representation of a received tsCue.
+type listenCueOutput struct {
+	Time   time.Time `json:"time"`
+	Source string    `json:"source"`
+	cueOutput
+}
+
+// printListenCue prints a received tsCue in the requested format.
+func printListenCue(w io.Writer, format string, t time.Time, src net.Addr, cue tsCue) error {
+	if format == "text" {
+		if _, err := fmt.Fprintf(w, "time: %s, source: %s\n", t.Format(time.RFC3339Nano), src); err != nil {
+			return err
+		}
+		_, err := printCue(w, format, cue)
+		return err
+	}
+
+	out, _ := newCueOutput(cue)
+	b, err := json.Marshal(&listenCueOutput{Time: t.UTC(), Source: src.String(), cueOutput: out})
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
@@ -0,0 +1,191 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Comcast/scte35-go/cmd"
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+// rtpPacket wraps payload in a version 2 RTP header (RFC 3550) with an
+// optional header extension and padding.
+func rtpPacket(seq uint16, payload []byte, extension, padding bool) []byte {
+	b := []byte{0x80, 33, byte(seq >> 8), byte(seq), 0, 0, 0, 0, 0, 0, 0, 1}
+	if extension {
+		b[0] |= 0x10
+		b = append(b, 0xBE, 0xDE, 0x00, 0x01, 0x01, 0x02, 0x03, 0x04)
+	}
+	b = append(b, payload...)
+	if padding {
+		b[0] |= 0x20
+		b = append(b, 0x00, 0x00, 0x03)
+	}
+	return b
+}
+
+func TestListen(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	short, err := scte35.DecodeBase64("/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==")
+	require.NoError(t, err)
+	shortBytes, err := short.Encode()
+	require.NoError(t, err)
+	long, longBytes := longSignal(t, 1)
+
+	psi := patPMTPackets(0x86, nil)
+
+	type expected struct {
+		packet int
+		sis    *scte35.SpliceInfoSection
+	}
+
+	cases := map[string]struct {
+		datagrams [][]byte
+		count     int
+		expected  []expected
+	}{
+		"Raw": {
+			datagrams: [][]byte{join(psi, sectionPackets(scte35PID, 0, shortBytes))},
+			count:     1,
+			expected:  []expected{{packet: 2, sis: short}},
+		},
+		"RTP": {
+			datagrams: [][]byte{rtpPacket(1, join(psi, sectionPackets(scte35PID, 0, shortBytes)), false, false)},
+			count:     1,
+			expected:  []expected{{packet: 2, sis: short}},
+		},
+		"RTP Extension And Padding": {
+			datagrams: [][]byte{rtpPacket(1, join(psi, sectionPackets(scte35PID, 0, shortBytes)), true, true)},
+			count:     1,
+			expected:  []expected{{packet: 2, sis: short}},
+		},
+		"Raw And RTP": {
+			// a section spanning datagrams, followed by another cue
+			datagrams: [][]byte{
+				join(psi),
+				join(sectionPackets(scte35PID, 0, longBytes)[:2]),
+				rtpPacket(1, join(sectionPackets(scte35PID, 0, longBytes)[2:], sectionPackets(scte35PID, 3, shortBytes)), false, false),
+			},
+			count: 2,
+			expected: []expected{
+				{packet: 4, sis: long},
+				{packet: 5, sis: short},
+			},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			// the listening address is read from stderr
+			stderr, stderrW := io.Pipe()
+			var stdout bytes.Buffer
+			root := cmd.SCTE35()
+			root.SetOut(&stdout)
+			root.SetErr(stderrW)
+			root.SetArgs([]string{"listen", "--count", strconv.Itoa(c.count), "udp://127.0.0.1:0"})
+
+			done := make(chan error, 1)
+			go func() {
+				done <- root.Execute()
+				_ = stderrW.Close()
+			}()
+
+			line, err := bufio.NewReader(stderr).ReadString('\n')
+			require.NoError(t, err)
+			addr, ok := strings.CutPrefix(strings.TrimSpace(line), "Listening on ")
+			require.True(t, ok, line)
+
+			conn, err := net.Dial("udp", addr)
+			require.NoError(t, err)
+			defer func() { _ = conn.Close() }()
+			for _, d := range c.datagrams {
+				_, err := conn.Write(d)
+				require.NoError(t, err)
+			}
+
+			// --count exits once the cues have been received
+			select {
+			case err := <-done:
+				require.NoError(t, err)
+			case <-time.After(5 * time.Second):
+				t.Fatal("listen did not exit after --count cues")
+			}
+
+			lines := strings.Split(strings.TrimSuffix(stdout.String(), "\n"), "\n")
+			require.Len(t, lines, len(c.expected))
+			for i, e := range c.expected {
+				var res struct {
+					Time   time.Time `json:"time"`
+					Source string    `json:"source"`
+					tsCueResult
+				}
+				require.NoError(t, json.Unmarshal([]byte(lines[i]), &res))
+				require.WithinDuration(t, time.Now(), res.Time, 5*time.Second)
+				require.Equal(t, conn.LocalAddr().String(), res.Source)
+				require.Empty(t, res.Error)
+				require.Equal(t, e.packet, res.Packet)
+				require.Equal(t, uint16(scte35PID), res.PID)
+				require.Empty(t, scte35.Diff(e.sis, res.SpliceInfoSection))
+			}
+		})
+	}
+}
+
+func TestListen_Args(t *testing.T) {
+	cases := map[string]struct {
+		args []string
+		err  string
+	}{
+		"Unsupported Scheme": {
+			args: []string{"listen", "tcp://127.0.0.1:5000"},
+			err:  `unsupported scheme: "tcp" (expected udp://)`,
+		},
+		"Missing Port": {
+			args: []string{"listen", "udp://127.0.0.1"},
+			err:  `missing port: "udp://127.0.0.1"`,
+		},
+		"Multicast Port 0": {
+			args: []string{"listen", "udp://239.1.1.1:0"},
+			err:  `missing port: "udp://239.1.1.1:0"`,
+		},
+		"Unicast Interface": {
+			args: []string{"listen", "--interface", "lo", "udp://127.0.0.1:0"},
+			err:  "--interface requires a multicast group",
+		},
+		"Unsupported Format": {
+			args: []string{"listen", "--out", "xml", "udp://127.0.0.1:0"},
+			err:  `unsupported --out: "xml"`,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			_, err := execute(t, nil, c.args...)
+			require.EqualError(t, err, c.err)
+		})
+	}
+}
@@ -24,9 +24,19 @@ func SCTE35() *cobra.Command {
 	c := &cobra.Command{
 		Use:   "scte35",
 		Short: "SCTE-35 CLI",
+		// errors are reported by main
+		SilenceErrors: true,
 	}
 
+	c.AddCommand(buildCommand())
+	c.AddCommand(convertCommand())
 	c.AddCommand(decodeCommand())
+	c.AddCommand(diffCommand())
 	c.AddCommand(encodeCommand())
+	c.AddCommand(hlsCommand())
+	c.AddCommand(listenCommand())
+	c.AddCommand(serveCommand())
+	c.AddCommand(tsCommand())
+	c.AddCommand(validateCommand())
 	return c
 }
@@ -0,0 +1,43 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/cmd"
+)
+
+// execute runs the scte35 command with args, reading stdin from in, and
+// returns the output written to stdout and the error returned by the command.
+func execute(t *testing.T, in io.Reader, args ...string) (string, error) {
+	t.Helper()
+	if in == nil {
+		in = strings.NewReader("")
+	}
+	var out, stderr bytes.Buffer
+	c := cmd.SCTE35()
+	c.SetIn(in)
+	c.SetOut(&out)
+	c.SetErr(&stderr)
+	c.SetArgs(args)
+	err := c.Execute()
+	return out.String(), err
+}
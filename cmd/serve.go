@@ -0,0 +1,98 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/Comcast/scte35-go/pkg/scte35http"
+	"github.com/spf13/cobra"
+)
+
+// serveShutdownTimeout is the time allowed for in-flight requests to complete
+// when the server is stopped.
+const serveShutdownTimeout = 5 * time.Second
+
+// serveCommand returns the command for `scte35 serve`
+func serveCommand() *cobra.Command {
+	var addr string
+	var maxRequestBytes int64
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve a REST API for decoding, encoding, validating and converting",
+		Long: "Serve a REST API with the following endpoints:\n\n" +
+			"  GET  /health                 health check\n" +
+			"  POST /decode                 decode the signal in the request body\n" +
+			"  POST /encode                 encode the signal as base-64 and hex\n" +
+			"  POST /validate?rules=        validate the signal (see validate)\n" +
+			"  POST /convert?to=&from=      convert the signal (see convert)\n\n" +
+			"Request bodies may use any representation supported by convert.\n" +
+			"Responses are JSON, XML or text according to the Accept header,\n" +
+			"defaulting to JSON. The server stops gracefully on SIGINT or SIGTERM.",
+		Args:         cobra.NoArgs,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if maxRequestBytes <= 0 {
+				return fmt.Errorf("--max-request-bytes must be positive")
+			}
+
+			srv := &http.Server{
+				Addr:              addr,
+				Handler:           &scte35http.Handler{MaxRequestBytes: maxRequestBytes},
+				ReadHeaderTimeout: 10 * time.Second,
+				ReadTimeout:       30 * time.Second,
+				WriteTimeout:      30 * time.Second,
+				IdleTimeout:       2 * time.Minute,
+			}
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+
+			errs := make(chan error, 1)
+			go func() {
+				_, _ = fmt.Fprintf(os.Stderr, "Listening on %s\n", addr)
+				errs <- srv.ListenAndServe()
+			}()
+
+			select {
+			case err := <-errs:
+				return err
+			case <-ctx.Done():
+			}
+
+			shutdownCtx, cancel := context.WithTimeout(context.Background(), serveShutdownTimeout)
+			defer cancel()
+			if err := srv.Shutdown(shutdownCtx); err != nil {
+				return err
+			}
+			if err := <-errs; !errors.Is(err, http.ErrServerClosed) {
+				return err
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&addr, "addr", "localhost:8080", "address to listen on")
+	cmd.Flags().Int64Var(&maxRequestBytes, "max-request-bytes", scte35http.DefaultMaxRequestBytes, "maximum request body size")
+	return cmd
+}
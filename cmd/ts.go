@@ -0,0 +1,142 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// tsCommand returns the command for `scte35 ts`
+func tsCommand() *cobra.Command {
+	var format string
+	cmd := &cobra.Command{
+		Use:   "ts [filename]",
+		Short: "Decode the splice_info_sections in an MPEG-2 transport stream",
+		Long: "Decode the splice_info_sections in an MPEG-2 transport stream file (or\n" +
+			"stdin). SCTE 35 PIDs are located via the PAT and PMT (stream_type 0x86\n" +
+			"or a CUEI registration_descriptor). Each cue is printed with the index\n" +
+			"of the packet completing it, the PID and the most recent PCR and video\n" +
+			"PTS. Duplicate packets are dropped and partial sections are discarded\n" +
+			"when packets are lost.",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("requires at most one transport stream file")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			in := c.InOrStdin()
+			if len(args) == 1 && args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer func() { _ = f.Close() }()
+				in = f
+			}
+
+			d := newTSDemuxer()
+			total, failed := 0, 0
+			err := readTSPackets(in, func(pkt []byte) error {
+				for _, cue := range d.Write(pkt) {
+					total++
+					ok, err := printCue(c.OutOrStdout(), format, cue)
+					if err != nil {
+						return err
+					}
+					if !ok {
+						failed++
+					}
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if failed > 0 {
+				return fmt.Errorf("%d of %d signals failed to decode", failed, total)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	return cmd
+}
+
+// cueOutput is the JSON and XML representation of a tsCue.
+type cueOutput struct {
+	XMLName           xml.Name                  `xml:"Cue" json:"-"`
+	Packet            int                       `xml:"packet,attr" json:"packet"`
+	PID               uint16                    `xml:"pid,attr" json:"pid"`
+	PCR               *uint64                   `xml:"pcr,attr,omitempty" json:"pcr,omitempty"`
+	PTS               *uint64                   `xml:"pts,attr,omitempty" json:"pts,omitempty"`
+	Error             string                    `xml:"error,attr,omitempty" json:"error,omitempty"`
+	SpliceInfoSection *scte35.SpliceInfoSection `xml:"SpliceInfoSection" json:"spliceInfoSection"`
+}
+
+// newCueOutput decodes a tsCue, returning its cueOutput and any decoding
+// error.
+func newCueOutput(cue tsCue) (cueOutput, error) {
+	sis := &scte35.SpliceInfoSection{}
+	err := sis.Decode(cue.Section)
+	out := cueOutput{Packet: cue.Packet, PID: cue.PID, PCR: cue.PCR, PTS: cue.PTS, SpliceInfoSection: sis}
+	if err != nil {
+		out.Error = err.Error()
+	}
+	return out, err
+}
+
+// printCue decodes and prints a tsCue in the requested format, returning
+// false if the cue could not be decoded.
+func printCue(w io.Writer, format string, cue tsCue) (bool, error) {
+	out, decodeErr := newCueOutput(cue)
+	sis := out.SpliceInfoSection
+
+	var err error
+	switch format {
+	case "json", "xml":
+		var b []byte
+		if format == "json" {
+			b, _ = json.MarshalIndent(&out, "", "\t")
+		} else {
+			b, _ = xml.MarshalIndent(&out, "", "\t")
+		}
+		_, err = fmt.Fprintf(w, "%s\n", b)
+	default:
+		_, err = fmt.Fprintf(w, "packet: %d, pid: %#04x, pcr: %s, pts: %s\n%s\n", cue.Packet, cue.PID, optionalTicks(cue.PCR), optionalTicks(cue.PTS), sis.Table("", "\t"))
+		if err == nil && decodeErr != nil {
+			_, err = fmt.Fprintf(w, "Error: %s\n\n", decodeErr)
+		}
+	}
+	return decodeErr == nil, err
+}
+
+// optionalTicks formats an optional 90KHz timestamp.
+func optionalTicks(ticks *uint64) string {
+	if ticks == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%d (%s)", *ticks, scte35.TicksToDuration(*ticks))
+}
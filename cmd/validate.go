@@ -0,0 +1,161 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/spf13/cobra"
+)
+
+// validateCommand returns the command for `scte35 validate`
+func validateCommand() *cobra.Command {
+	var format string
+	var file string
+	var binary bool
+	var rules []string
+	cmd := &cobra.Command{
+		Use:   "validate [signal]",
+		Short: "Check a splice_info_section for conformance with ANSI/SCTE 35",
+		Long: "Check a splice_info_section for conformance with ANSI/SCTE 35, printing\n" +
+			"any findings with their severity. An error is returned if any signal\n" +
+			"has a finding with a severity of error.\n\n" +
+			"If no signal is provided, signals are read from --file or stdin, as with\n" +
+			"decode.\n\n" +
+			"The following rules are run by default:\n\n" +
+			"  crc                   CRC_32 is valid\n" +
+			"  reserved-bits         reserved bits are set to 1\n" +
+			"  segmentation          segment_num, segments_expected and sub_segment\n" +
+			"                        fields are consistent with the\n" +
+			"                        segmentation_type_id, Content Identification\n" +
+			"                        carries a segmentation_upid and\n" +
+			"                        segmentation_upids are well formed\n" +
+			"  duration              break_duration and segmentation_duration are\n" +
+			"                        present where required\n" +
+			"  descriptor-placement  splice_descriptors are carried with an applicable\n" +
+			"                        splice_command\n\n" +
+			"Use --rules to run only the named rules, or to disable a rule by prefixing\n" +
+			"its name with '-' (ie, --rules=-reserved-bits).",
+		Args: func(_ *cobra.Command, args []string) error {
+			if len(args) > 1 {
+				return fmt.Errorf("requires at most one binary signal")
+			}
+			if len(args) == 1 && (file != "" || binary) {
+				return fmt.Errorf("--file and --binary cannot be used with a signal argument")
+			}
+			return nil
+		},
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			enabled, err := scte35.ParseValidationRules(rules)
+			if err != nil {
+				return err
+			}
+
+			total, failed := 0, 0
+			validate := func(signal string, b []byte, err error) error {
+				res := validateResult{Index: total, Signal: signal}
+				if err != nil {
+					res.Findings = []scte35.Finding{{Rule: scte35.RuleDecode, Severity: scte35.SeverityError, Message: err.Error()}}
+				} else {
+					_, res.Findings = scte35.Validate(b, enabled...)
+				}
+				total++
+				if res.failed() {
+					failed++
+				}
+				return printValidateResult(c.OutOrStdout(), format, res)
+			}
+
+			if len(args) == 1 {
+				b, err := scte35.SignalBytes([]byte(args[0]), scte35.RepresentationAuto)
+				if err := validate(args[0], b, err); err != nil {
+					return err
+				}
+			} else {
+				if err := checkStdin(c, file); err != nil {
+					return err
+				}
+				if err := readSignals(c.InOrStdin(), file, binary, validate); err != nil {
+					return err
+				}
+			}
+
+			if failed > 0 {
+				return fmt.Errorf("%d of %d signals failed validation", failed, total)
+			}
+			return nil
+		},
+	}
+	cmd.PersistentFlags().StringVar(&format, "out", "text", "specify alternative output format (json, xml, text)")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "read signals from a file instead of stdin (- for stdin)")
+	cmd.Flags().BoolVar(&binary, "binary", false, "input contains raw binary splice_info_sections")
+	cmd.Flags().StringSliceVar(&rules, "rules", nil, "rules to run, or disable with a '-' prefix (default all)")
+	return cmd
+}
+
+// validateResult is the JSON and XML representation of a validated signal.
+type validateResult struct {
+	XMLName  xml.Name         `xml:"Validation" json:"-"`
+	Index    int              `xml:"index,attr" json:"index"`
+	Signal   string           `xml:"signal,attr" json:"signal"`
+	Findings []scte35.Finding `xml:"Finding" json:"findings"`
+}
+
+// failed returns true if any finding is an error.
+func (r *validateResult) failed() bool {
+	for _, f := range r.Findings {
+		if f.Severity == scte35.SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// printValidateResult prints a validateResult in the requested format. JSON is
+// written as one object per line (NDJSON).
+func printValidateResult(w io.Writer, format string, res validateResult) error {
+	switch format {
+	case "json":
+		if res.Findings == nil {
+			res.Findings = []scte35.Finding{}
+		}
+		return json.NewEncoder(w).Encode(&res)
+	case "xml":
+		b, _ := xml.MarshalIndent(&res, "", "\t")
+		_, err := fmt.Fprintf(w, "%s\n", b)
+		return err
+	default:
+		if len(res.Findings) == 0 {
+			_, err := fmt.Fprintf(w, "%s: ok\n", res.Signal)
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%s:\n", res.Signal); err != nil {
+			return err
+		}
+		for _, f := range res.Findings {
+			if _, err := fmt.Fprintf(w, "\t%s\n", f); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
@@ -0,0 +1,112 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package cmd_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const ok = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+	// legacy splice_command_length
+	const warning = "/DA8AAAAAAAAAP///wb+06ACpQAmAiRDVUVJAACcHX//AACky4AMEERJU0NZTVdGMDQ1MjAwMEgxAQEMm4c0"
+	// invalid CRC_32
+	const invalid = "/DA4AAAAAAAAAP/wFAUABDEAf+//mWEhzP4Azf5gAQAAAAATAhFDVUVJAAAAAX+/AQIwNAEAAKeYO3Q="
+
+	file := filepath.Join(t.TempDir(), "signals.txt")
+	require.NoError(t, os.WriteFile(file, []byte(ok+"\n"+invalid+"\n"), 0o600))
+
+	cases := map[string]struct {
+		args     []string
+		in       io.Reader
+		expected string
+		err      string
+	}{
+		"OK": {
+			args:     []string{"validate", ok},
+			expected: ok + ": ok\n",
+		},
+		"Hex Upper Case Prefix": {
+			args:     []string{"validate", "0XFC3034000000000000FFFFF00506FE72BD0050001E021C435545494800008E7FCF0001A599B00808000000002CA0A18A3402009AC9D17E"},
+			expected: "0XFC3034000000000000FFFFF00506FE72BD0050001E021C435545494800008E7FCF0001A599B00808000000002CA0A18A3402009AC9D17E: ok\n",
+		},
+		"Warnings": {
+			args: []string{"validate", warning},
+			expected: warning + ":\n" +
+				"\twarning: [decode] legacy splice_command_length 0xfff\n" +
+				"\twarning: [duration] segmentation_descriptor[0]: segmentation_duration is not used for Provider Advertisement End\n",
+		},
+		"Errors": {
+			args: []string{"validate", invalid},
+			expected: invalid + ":\n" +
+				"\terror: [crc] splice_info_section: CRC_32 not valid\n" +
+				"\twarning: [segmentation] segmentation_descriptor[0]: segmentation_upid_type 0x01 (User Defined) is deprecated\n",
+			err: "1 of 1 signals failed validation",
+		},
+		"Rule Disabled": {
+			args:     []string{"validate", "--rules=-crc,-segmentation", invalid},
+			expected: invalid + ": ok\n",
+		},
+		"Rule Selected": {
+			args:     []string{"validate", "--rules=reserved-bits", invalid},
+			expected: invalid + ": ok\n",
+		},
+		"Unknown Rule": {
+			args: []string{"validate", "--rules=bogus", ok},
+			err:  `unknown rule: "bogus"`,
+		},
+		"Undecodable": {
+			args:     []string{"validate", "bogus"},
+			expected: "bogus:\n\terror: [decode] invalid or unsupported encoding\n",
+			err:      "1 of 1 signals failed validation",
+		},
+		"Stdin": {
+			args:     []string{"validate"},
+			in:       strings.NewReader(ok + "\n" + warning + "\n"),
+			expected: ok + ": ok\n" + warning + ":\n" + "\twarning: [decode] legacy splice_command_length 0xfff\n" + "\twarning: [duration] segmentation_descriptor[0]: segmentation_duration is not used for Provider Advertisement End\n",
+		},
+		"File": {
+			args: []string{"validate", "--file", file, "--rules=crc", "--out", "json"},
+			expected: `{"index":0,"signal":"` + ok + `","findings":[]}` + "\n" +
+				`{"index":1,"signal":"` + invalid + `","findings":[{"rule":"crc","severity":"error","message":"splice_info_section: CRC_32 not valid"}]}` + "\n",
+			err: "1 of 2 signals failed validation",
+		},
+		"Signal With Binary": {
+			args: []string{"validate", "--binary", ok},
+			err:  "--file and --binary cannot be used with a signal argument",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			out, err := execute(t, c.in, c.args...)
+			if c.err == "" {
+				require.NoError(t, err)
+			} else {
+				require.ErrorContains(t, err, c.err)
+			}
+			require.Equal(t, c.expected, out)
+		})
+	}
+}
@@ -25,29 +25,19 @@ import (
 
 func main() {
 	// start with a signal
-	sis := scte35.SpliceInfoSection{
-		SpliceCommand: scte35.NewTimeSignal(0x072bd0050),
-		SpliceDescriptors: []scte35.SpliceDescriptor{
-			&scte35.SegmentationDescriptor{
-				DeliveryRestrictions: &scte35.DeliveryRestrictions{
-					NoRegionalBlackoutFlag: true,
-					ArchiveAllowedFlag:     true,
-					DeviceRestrictions:     scte35.DeviceRestrictionsNone,
-				},
-				SegmentationEventID: uint32(0x4800008e),
-				SegmentationTypeID:  scte35.SegmentationTypeProviderPOStart,
-				SegmentationUPIDs: []scte35.SegmentationUPID{
-					scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeTI, []byte("78511452")),
-				},
-				SegmentNum: 2,
-			},
-		},
-		EncryptedPacket: scte35.EncryptedPacket{
-			EncryptionAlgorithm: scte35.EncryptionAlgorithmNone,
-			CWIndex:             255,
-		},
-		Tier:    4095,
-		SAPType: 3,
+	sis, err := scte35.NewTimeSignalBuilder(0x072bd0050).
+		WithSegmentation(0x4800008e, scte35.SegmentationTypeProviderPOStart).
+		WithDeliveryRestrictions(scte35.DeliveryRestrictions{
+			NoRegionalBlackoutFlag: true,
+			ArchiveAllowedFlag:     true,
+			DeviceRestrictions:     scte35.DeviceRestrictionsNone,
+		}).
+		WithSegmentNum(2, 0).
+		WithUPID(scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeTI, []byte("78511452"))).
+		Build()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Error building signal: %s\n", err)
+		os.Exit(1)
 	}
 
 	// encode it
@@ -28,7 +28,7 @@ import (
 func main() {
 	scte35 := cmd.SCTE35()
 	if err := scte35.Execute(); err != nil {
-		_, _ = fmt.Fprintf(os.Stderr, "Error: %s", err)
+		_, _ = fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		os.Exit(-1)
 	}
 }
@@ -0,0 +1,145 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+)
+
+const (
+	// AdIDLength is the length of an Ad-ID, in bytes.
+	AdIDLength = 12
+	// AdIDSuffixHD is the Ad-ID suffix for high definition assets.
+	AdIDSuffixHD = "H"
+	// AdIDSuffix3D is the Ad-ID suffix for 3D assets.
+	AdIDSuffix3D = "D"
+)
+
+// ParseAdID parses an Ad-ID. An Ad-ID is 12 characters; 4 alpha characters
+// (company identification prefix) followed by 8 alphanumeric characters, the
+// last of which may be an HD ("H") or 3D ("D") suffix.
+func ParseAdID(s string) (AdID, error) {
+	if len(s) != AdIDLength {
+		return AdID{}, fmt.Errorf("ad-id: %w: expected %d characters, got %d", ErrInvalidUPID, AdIDLength, len(s))
+	}
+	if !isAlpha(s[:4]) {
+		return AdID{}, fmt.Errorf("ad-id: %w: prefix %q is not alphabetic", ErrInvalidUPID, s[:4])
+	}
+	if !isAlphanumeric(s[4:]) {
+		return AdID{}, fmt.Errorf("ad-id: %w: code %q is not alphanumeric", ErrInvalidUPID, s[4:])
+	}
+
+	id := AdID{Prefix: s[:4], Code: s[4:]}
+	switch s[11:] {
+	case AdIDSuffixHD, AdIDSuffix3D:
+		id.Code = s[4:11]
+		id.Suffix = s[11:]
+	}
+	return id, nil
+}
+
+// AdID is a structured Ad-ID (segmentation_upid_type 0x03).
+type AdID struct {
+	// Prefix is the 4 character company identification prefix.
+	Prefix string
+	// Code is the unique code assigned by the company. It is 8 characters, or
+	// 7 characters when a Suffix is present.
+	Code string
+	// Suffix is the optional HD ("H") or 3D ("D") suffix.
+	Suffix string
+}
+
+// String returns the Ad-ID as a string.
+func (id AdID) String() string {
+	return id.Prefix + id.Code + id.Suffix
+}
+
+// AdID returns the Value of an Ad-ID SegmentationUPID as an AdID.
+func (upid *SegmentationUPID) AdID() (AdID, error) {
+	if upid.Type != SegmentationUPIDTypeAdID {
+		return AdID{}, fmt.Errorf("ad-id: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseAdID(upid.Value)
+}
+
+// ParseISCI parses an ISCI. An ISCI is 8 characters; 4 alpha characters
+// followed by 4 numbers.
+func ParseISCI(s string) (ISCI, error) {
+	if len(s) != 8 {
+		return ISCI{}, fmt.Errorf("isci: %w: expected 8 characters, got %d", ErrInvalidUPID, len(s))
+	}
+	if !isAlpha(s[:4]) {
+		return ISCI{}, fmt.Errorf("isci: %w: prefix %q is not alphabetic", ErrInvalidUPID, s[:4])
+	}
+	if !isNumeric(s[4:]) {
+		return ISCI{}, fmt.Errorf("isci: %w: code %q is not numeric", ErrInvalidUPID, s[4:])
+	}
+	return ISCI{Prefix: s[:4], Code: s[4:]}, nil
+}
+
+// ISCI is a structured ISCI (segmentation_upid_type 0x02).
+// Deprecated.
+type ISCI struct {
+	// Prefix is the 4 character company prefix.
+	Prefix string
+	// Code is the 4 digit commercial code.
+	Code string
+}
+
+// String returns the ISCI as a string.
+func (id ISCI) String() string {
+	return id.Prefix + id.Code
+}
+
+// ISCI returns the Value of an ISCI SegmentationUPID as an ISCI.
+func (upid *SegmentationUPID) ISCI() (ISCI, error) {
+	if upid.Type != SegmentationUPIDTypeISCI {
+		return ISCI{}, fmt.Errorf("isci: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseISCI(upid.Value)
+}
+
+// isAlpha returns true if s contains only upper-case ASCII letters.
+func isAlpha(s string) bool {
+	for i := range len(s) {
+		if s[i] < 'A' || s[i] > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNumeric returns true if s contains only ASCII digits.
+func isNumeric(s string) bool {
+	for i := range len(s) {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// isAlphanumeric returns true if s contains only upper-case ASCII letters and
+// digits.
+func isAlphanumeric(s string) bool {
+	for i := range len(s) {
+		if !isAlpha(s[i:i+1]) && !isNumeric(s[i:i+1]) {
+			return false
+		}
+	}
+	return true
+}
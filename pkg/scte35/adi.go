@@ -0,0 +1,119 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// ADIElementPreview is the ADI element for a Preview.
+	ADIElementPreview = "PREVIEW"
+	// ADIElementMPEG2HD is the ADI element for MPEG-2 HD content.
+	ADIElementMPEG2HD = "MPEG2HD"
+	// ADIElementMPEG2SD is the ADI element for MPEG-2 SD content.
+	ADIElementMPEG2SD = "MPEG2SD"
+	// ADIElementAVCHD is the ADI element for AVC HD content.
+	ADIElementAVCHD = "AVCHD"
+	// ADIElementAVCSD is the ADI element for AVC SD content.
+	ADIElementAVCSD = "AVCSD"
+	// ADIElementHEVCSD is the ADI element for HEVC SD content.
+	ADIElementHEVCSD = "HEVCSD"
+	// ADIElementHEVCHD is the ADI element for HEVC HD content.
+	ADIElementHEVCHD = "HEVCHD"
+	// ADIElementSignal is the ADI element for a Signal.
+	ADIElementSignal = "SIGNAL"
+	// ADIElementPlacementOpportunity is the ADI element for a Placement
+	// Opportunity.
+	ADIElementPlacementOpportunity = "PO"
+	// ADIElementBlackout is the ADI element for a Blackout.
+	ADIElementBlackout = "BLACKOUT"
+	// ADIElementOther is the ADI element for anything else.
+	ADIElementOther = "OTHER"
+)
+
+// ParseADI parses an ADI from its abbreviated <element>:<identifier> syntax,
+// as defined in section 10.3.3.2.
+func ParseADI(s string) (ADI, error) {
+	element, identifier, ok := strings.Cut(s, ":")
+	if !ok {
+		return ADI{}, fmt.Errorf("adi: %w: expected <element>:<identifier>, got %q", ErrInvalidUPID, s)
+	}
+
+	adi := ADI{
+		Element:    strings.TrimSpace(element),
+		Identifier: strings.TrimSpace(identifier),
+	}
+	switch adi.Element {
+	case ADIElementPreview,
+		ADIElementMPEG2HD,
+		ADIElementMPEG2SD,
+		ADIElementAVCHD,
+		ADIElementAVCSD,
+		ADIElementHEVCSD,
+		ADIElementHEVCHD,
+		ADIElementSignal,
+		ADIElementPlacementOpportunity,
+		ADIElementBlackout,
+		ADIElementOther:
+	default:
+		return ADI{}, fmt.Errorf("adi: %w: unknown element %q", ErrInvalidUPID, adi.Element)
+	}
+
+	if adi.Identifier == "" {
+		return ADI{}, fmt.Errorf("adi: %w: identifier is empty", ErrInvalidUPID)
+	}
+	for i := range len(adi.Identifier) {
+		if adi.Identifier[i] < 0x20 || adi.Identifier[i] > 0x7E {
+			return ADI{}, fmt.Errorf("adi: %w: identifier %q is not printable ASCII", ErrInvalidUPID, adi.Identifier)
+		}
+	}
+	return adi, nil
+}
+
+// ADI is a structured CableLabs metadata identifier (segmentation_upid_type
+// 0x09).
+type ADI struct {
+	// Element is one of the ADIElement constants (ie, SIGNAL).
+	Element string
+	// Identifier identifies the element. For CableLabs Content 1.1 metadata,
+	// this is <providerID>/<assetID>.
+	Identifier string
+}
+
+// SegmentationUPID returns this ADI as a SegmentationUPID.
+func (adi ADI) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeADI,
+		Format: SegmentationUPIDFormatText,
+		Value:  adi.String(),
+	}
+}
+
+// String returns the ADI in its abbreviated <element>:<identifier> syntax.
+func (adi ADI) String() string {
+	return adi.Element + ":" + adi.Identifier
+}
+
+// ADI returns the Value of an ADI SegmentationUPID as an ADI.
+func (upid *SegmentationUPID) ADI() (ADI, error) {
+	if upid.Type != SegmentationUPIDTypeADI {
+		return ADI{}, fmt.Errorf("adi: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseADI(upid.Value)
+}
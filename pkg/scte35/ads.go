@@ -0,0 +1,210 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// ADSTypeLocalAvailability is the ADS type for Local Operator
+	// Availability.
+	ADSTypeLocalAvailability = "LA"
+	// ADSTypeNationalAddressable is the ADS type for National Addressable
+	// advertising.
+	ADSTypeNationalAddressable = "NA"
+	// ADSTypeNationalReplacement is the ADS type for National Replacement
+	// advertising.
+	ADSTypeNationalReplacement = "NR"
+	// ADSTypeCreativeVersioning is the ADS type for Creative Versioning
+	// advertising.
+	ADSTypeCreativeVersioning = "CV"
+	// ADSTypeC3 is the ADS type for Nielsen VOD ratings credit for 3 days.
+	ADSTypeC3 = "C3"
+	// ADSTypeC7 is the ADS type for Nielsen VOD ratings credit for 7 days.
+	ADSTypeC7 = "C7"
+
+	// upidKeyValueSeparator separates a key from its value.
+	upidKeyValueSeparator = "="
+	// upidKeyValueDelimiter separates key-value pairs.
+	upidKeyValueDelimiter = "&"
+	// upidSerialSeparator separates multiple values of a key.
+	upidSerialSeparator = ";"
+)
+
+// ParseADS parses an ADS from its key-value pair syntax (ie,
+// type=LA&dur=60000&pos=90000&tier=1), as defined in section 10.3.3.5. Keys
+// other than type, pos, dur and tier are permitted.
+func ParseADS(s string) (*ADS, error) {
+	kvs, err := parseUPIDKeyValues(s)
+	if err != nil {
+		return nil, fmt.Errorf("ads: %w", err)
+	}
+	ads := &ADS{UPIDKeyValues: kvs}
+	for _, t := range ads.Get("type") {
+		switch t {
+		case ADSTypeLocalAvailability,
+			ADSTypeNationalAddressable,
+			ADSTypeNationalReplacement,
+			ADSTypeCreativeVersioning,
+			ADSTypeC3,
+			ADSTypeC7:
+		default:
+			return nil, fmt.Errorf("ads: %w: unknown type %q", ErrInvalidUPID, t)
+		}
+	}
+	for _, k := range []string{"pos", "dur", "tier"} {
+		if err := kvs.validateUint(k); err != nil {
+			return nil, fmt.Errorf("ads: %w", err)
+		}
+	}
+	return ads, nil
+}
+
+// ADS is structured advertising information (segmentation_upid_type 0x0E).
+type ADS struct {
+	UPIDKeyValues
+}
+
+// Type returns the types of advertising referenced in the Segment (ie, LA).
+func (ads *ADS) Type() []string {
+	return ads.Get("type")
+}
+
+// Position returns the start point of the referenced advertising relative to
+// the start of the Segment.
+func (ads *ADS) Position() (time.Duration, bool) {
+	return ads.milliseconds("pos")
+}
+
+// Duration returns the duration of the referenced advertising.
+func (ads *ADS) Duration() (time.Duration, bool) {
+	return ads.milliseconds("dur")
+}
+
+// Tier returns the replacement authorization designation.
+func (ads *ADS) Tier() (uint64, bool) {
+	return ads.uint("tier")
+}
+
+// SegmentationUPID returns this ADS as a SegmentationUPID.
+func (ads *ADS) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeADS,
+		Format: SegmentationUPIDFormatText,
+		Value:  ads.String(),
+	}
+}
+
+// ADS returns the Value of an ADS SegmentationUPID as an ADS.
+func (upid *SegmentationUPID) ADS() (*ADS, error) {
+	if upid.Type != SegmentationUPIDTypeADS {
+		return nil, fmt.Errorf("ads: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseADS(upid.Value)
+}
+
+// UPIDKeyValue is a key and its values, as carried by ADS and SCR
+// SegmentationUPIDs.
+type UPIDKeyValue struct {
+	Key    string
+	Values []string
+}
+
+// UPIDKeyValues is an ordered list of UPIDKeyValue.
+type UPIDKeyValues []UPIDKeyValue
+
+// Get returns the values for the given key, or nil if the key is not present.
+func (kvs UPIDKeyValues) Get(key string) []string {
+	for _, kv := range kvs {
+		if kv.Key == key {
+			return kv.Values
+		}
+	}
+	return nil
+}
+
+// Set sets the values for the given key, replacing any existing values.
+func (kvs *UPIDKeyValues) Set(key string, values ...string) {
+	for i := range *kvs {
+		if (*kvs)[i].Key == key {
+			(*kvs)[i].Values = values
+			return
+		}
+	}
+	*kvs = append(*kvs, UPIDKeyValue{Key: key, Values: values})
+}
+
+// String returns the key-value pair syntax of these UPIDKeyValues.
+func (kvs UPIDKeyValues) String() string {
+	pairs := make([]string, len(kvs))
+	for i, kv := range kvs {
+		pairs[i] = kv.Key + upidKeyValueSeparator + strings.Join(kv.Values, upidSerialSeparator)
+	}
+	return strings.Join(pairs, upidKeyValueDelimiter)
+}
+
+// milliseconds returns the value of the given key as a duration in
+// milliseconds.
+func (kvs UPIDKeyValues) milliseconds(key string) (time.Duration, bool) {
+	i, ok := kvs.uint(key)
+	return time.Duration(i) * time.Millisecond, ok
+}
+
+// uint returns the value of the given key as an unsigned integer.
+func (kvs UPIDKeyValues) uint(key string) (uint64, bool) {
+	v := kvs.Get(key)
+	if len(v) != 1 {
+		return 0, false
+	}
+	i, err := strconv.ParseUint(v[0], 10, 64)
+	return i, err == nil
+}
+
+// validateUint returns an error if the given key is present but is not a
+// single unsigned integer.
+func (kvs UPIDKeyValues) validateUint(key string) error {
+	if v := kvs.Get(key); v != nil {
+		if _, ok := kvs.uint(key); !ok {
+			return fmt.Errorf("%w: %s %q is not an unsigned integer", ErrInvalidUPID, key, strings.Join(v, upidSerialSeparator))
+		}
+	}
+	return nil
+}
+
+// parseUPIDKeyValues parses key-value pairs using the ADS & SCR conventions.
+func parseUPIDKeyValues(s string) (UPIDKeyValues, error) {
+	if s == "" {
+		return nil, fmt.Errorf("%w: no key-value pairs", ErrInvalidUPID)
+	}
+
+	var kvs UPIDKeyValues
+	for _, pair := range strings.Split(s, upidKeyValueDelimiter) {
+		k, v, ok := strings.Cut(pair, upidKeyValueSeparator)
+		if !ok || k == "" {
+			return nil, fmt.Errorf("%w: malformed key-value pair %q", ErrInvalidUPID, pair)
+		}
+		if kvs.Get(k) != nil {
+			return nil, fmt.Errorf("%w: duplicate key %q", ErrInvalidUPID, k)
+		}
+		kvs = append(kvs, UPIDKeyValue{Key: k, Values: strings.Split(v, upidSerialSeparator)})
+	}
+	return kvs, nil
+}
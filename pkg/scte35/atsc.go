@@ -0,0 +1,165 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/bamiaux/iobit"
+)
+
+// atscContentIdentifierHeaderLength is the length of the fixed fields of an
+// ATSC_content_identifier(), in bytes.
+const atscContentIdentifierHeaderLength = 4
+
+// DecodeATSCContentIdentifier decodes a binary ATSC_content_identifier(), as
+// defined in ATSC A/57B.
+func DecodeATSCContentIdentifier(b []byte) (*ATSCContentIdentifier, error) {
+	if len(b) < atscContentIdentifierHeaderLength {
+		return nil, fmt.Errorf("atsc_content_identifier: %w: expected at least %d bytes, got %d", ErrInvalidUPID, atscContentIdentifierHeaderLength, len(b))
+	}
+
+	r := iobit.NewReader(b)
+	id := &ATSCContentIdentifier{}
+	id.TSID = r.Uint32(16)
+	id.Reserved = r.Uint32(2)
+	id.EndOfDay = r.Uint32(5)
+	id.UniqueFor = r.Uint32(9)
+	id.ContentID = r.LeftBytes()
+	return id, id.validate()
+}
+
+// ATSCContentIdentifier is a structured ATSC Content Identifier
+// (segmentation_upid_type 0x0B).
+type ATSCContentIdentifier struct {
+	// TSID is the transport_stream_id of the transport stream containing the
+	// service in which the content is carried.
+	TSID uint32
+	// Reserved contains the 2 reserved bits. Reserved bits shall be set to 1
+	// (0x03).
+	Reserved uint32
+	// EndOfDay is the hour of the day, in UTC (0 to 23), at which the
+	// broadcast day ends.
+	EndOfDay uint32
+	// UniqueFor is the number of broadcast days (1 to 511) for which
+	// ContentID is guaranteed to be unique.
+	UniqueFor uint32
+	// ContentID is the content identifier assigned by the broadcaster.
+	ContentID []byte
+}
+
+// Bytes returns the binary representation of this ATSC Content Identifier.
+func (id *ATSCContentIdentifier) Bytes() []byte {
+	buf := make([]byte, atscContentIdentifierHeaderLength+len(id.ContentID))
+	iow := iobit.NewWriter(buf)
+	iow.PutUint32(16, id.TSID)
+	iow.PutUint32(2, id.Reserved)
+	iow.PutUint32(5, id.EndOfDay)
+	iow.PutUint32(9, id.UniqueFor)
+	_, _ = iow.Write(id.ContentID)
+	_ = iow.Flush()
+	return buf
+}
+
+// SegmentationUPID returns this ATSC Content Identifier as a SegmentationUPID.
+func (id *ATSCContentIdentifier) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeATSC,
+		Format: SegmentationUPIDFormatBase64,
+		Value:  base64.StdEncoding.EncodeToString(id.Bytes()),
+	}
+}
+
+// atscContentIdentifierFields are the fields of an ATSC Content Identifier
+// included alongside the Value when marshalling a SegmentationUPID to JSON or
+// XML. ContentID is base-64 encoded.
+type atscContentIdentifierFields struct {
+	TSID      *uint32 `xml:"tsid,attr,omitempty" json:"tsid,omitempty"`
+	EndOfDay  *uint32 `xml:"endOfDay,attr,omitempty" json:"endOfDay,omitempty"`
+	UniqueFor *uint32 `xml:"uniqueFor,attr,omitempty" json:"uniqueFor,omitempty"`
+	ContentID *string `xml:"contentId,attr,omitempty" json:"contentId,omitempty"`
+}
+
+// fields returns the marshalled fields of this ATSC Content Identifier.
+func (id *ATSCContentIdentifier) fields() atscContentIdentifierFields {
+	contentID := base64.StdEncoding.EncodeToString(id.ContentID)
+	return atscContentIdentifierFields{
+		TSID:      &id.TSID,
+		EndOfDay:  &id.EndOfDay,
+		UniqueFor: &id.UniqueFor,
+		ContentID: &contentID,
+	}
+}
+
+// atscContentIdentifier returns an ATSC Content Identifier from its marshalled
+// fields, or nil if none are set. The reserved bits are set to 1.
+func (f *atscContentIdentifierFields) atscContentIdentifier() (*ATSCContentIdentifier, error) {
+	if f.TSID == nil && f.EndOfDay == nil && f.UniqueFor == nil && f.ContentID == nil {
+		return nil, nil
+	}
+	id := &ATSCContentIdentifier{Reserved: 0x03}
+	if f.TSID != nil {
+		id.TSID = *f.TSID
+	}
+	if f.EndOfDay != nil {
+		id.EndOfDay = *f.EndOfDay
+	}
+	if f.UniqueFor != nil {
+		id.UniqueFor = *f.UniqueFor
+	}
+	if f.ContentID != nil {
+		b, err := base64.StdEncoding.DecodeString(*f.ContentID)
+		if err != nil {
+			return nil, fmt.Errorf("atsc_content_identifier: %w: content_id: %s", ErrInvalidUPID, err)
+		}
+		id.ContentID = b
+	}
+	return id, nil
+}
+
+// validate returns an error if any field is out of range.
+func (id *ATSCContentIdentifier) validate() error {
+	if id.EndOfDay > 23 {
+		return fmt.Errorf("atsc_content_identifier: %w: end_of_day %d is not a valid hour", ErrInvalidUPID, id.EndOfDay)
+	}
+	if id.UniqueFor == 0 {
+		return fmt.Errorf("atsc_content_identifier: %w: unique_for must be at least 1", ErrInvalidUPID)
+	}
+	return nil
+}
+
+// writeTo writes the fields of this ATSC Content Identifier to the table.
+func (id *ATSCContentIdentifier) writeTo(t *table, indent int) {
+	t.row(indent, "tsid", id.TSID)
+	t.row(indent, "end_of_day", id.EndOfDay)
+	t.row(indent, "unique_for", id.UniqueFor)
+	t.row(indent, "content_id", fmt.Sprintf("%q", id.ContentID))
+}
+
+// ATSCContentIdentifier returns the Value of an ATSC Content Identifier
+// SegmentationUPID as an ATSCContentIdentifier.
+func (upid *SegmentationUPID) ATSCContentIdentifier() (*ATSCContentIdentifier, error) {
+	if upid.Type != SegmentationUPIDTypeATSC {
+		return nil, fmt.Errorf("atsc_content_identifier: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	b, err := upid.valueBytes()
+	if err != nil {
+		return nil, fmt.Errorf("atsc_content_identifier: %w", err)
+	}
+	return DecodeATSCContentIdentifier(b)
+}
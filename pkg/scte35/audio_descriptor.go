@@ -19,6 +19,7 @@ package scte35
 import (
 	"encoding/xml"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/bamiaux/iobit"
@@ -39,6 +40,13 @@ type AudioDescriptor struct {
 	AudioChannels []AudioChannel `xml:"http://www.scte.org/schemas/35 AudioChannel" json:"audioChannels"`
 }
 
+// Clone returns a deep copy of this AudioDescriptor.
+func (sd *AudioDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	c.AudioChannels = slices.Clone(sd.AudioChannels)
+	return &c
+}
+
 // Tag returns the splice_descriptor_tag.
 func (sd *AudioDescriptor) Tag() uint32 {
 	// ensure JSONType is set
@@ -107,19 +115,21 @@ func (sd *AudioDescriptor) length() int {
 // writeTo the given table.
 func (sd *AudioDescriptor) writeTo(t *table) {
 	t.row(0, "audio_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII))
-	t.row(1, "audio_count", len(sd.AudioChannels))
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII), 32)
+	t.field(1, "audio_count", len(sd.AudioChannels), 4)
+	t.reserved(1, 4)
 	for i, ac := range sd.AudioChannels {
 		t.row(1, "audio_channel["+strconv.Itoa(i)+"] {", nil)
-		t.row(2, "component_tag", ac.ComponentTag)
-		t.row(2, "iso_code", ac.ISOCode)
-		t.row(2, "bit_stream_mode", ac.BitStreamMode)
-		t.row(2, "num_channels", ac.NumChannels)
-		t.row(2, "full_srvc_audio", ac.FullSrvcAudio)
+		t.field(2, "component_tag", ac.ComponentTag, 8)
+		t.field(2, "iso_code", ac.ISOCode, 24)
+		t.field(2, "bit_stream_mode", ac.BitStreamMode, 3)
+		t.field(2, "num_channels", ac.NumChannels, 4)
+		t.field(2, "full_srvc_audio", ac.FullSrvcAudio, 1)
 		t.row(1, "}", nil)
 	}
+	t.end(1)
 	t.row(0, "}", nil)
 }
 
@@ -41,6 +41,12 @@ type AvailDescriptor struct {
 	ProviderAvailID uint32   `xml:"providerAvailId,attr" json:"providerAvailId"`
 }
 
+// Clone returns a deep copy of this AvailDescriptor.
+func (sd *AvailDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	return &c
+}
+
 // Tag returns the splice_descriptor_tag.
 func (sd *AvailDescriptor) Tag() uint32 {
 	// ensure JSONType is set
@@ -51,10 +57,11 @@ func (sd *AvailDescriptor) Tag() uint32 {
 // writeTo the given table.
 func (sd *AvailDescriptor) writeTo(t *table) {
 	t.row(0, "avail_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", AvailDescriptorTag))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII))
-	t.row(1, "provider_avail_id", sd.ProviderAvailID)
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", AvailDescriptorTag), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII), 32)
+	t.field(1, "provider_avail_id", sd.ProviderAvailID, 32)
+	t.end(1)
 	t.row(0, "}", nil)
 }
 
@@ -42,6 +42,12 @@ type BandwidthReservation struct {
 	JSONType uint32   `xml:"-" json:"type"`
 }
 
+// Clone returns a deep copy of this BandwidthReservation.
+func (cmd *BandwidthReservation) Clone() SpliceCommand {
+	c := *cmd
+	return &c
+}
+
 // Type returns the splice_command_type.
 func (cmd *BandwidthReservation) Type() uint32 {
 	// ensure JSONType is set
@@ -0,0 +1,328 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// maxTier is the maximum value of the 12-bit tier.
+	maxTier = uint32(0xFFF)
+	// maxSegmentationDuration is the maximum value of the 40-bit
+	// segmentation_duration.
+	maxSegmentationDuration = uint64(1<<40 - 1)
+)
+
+// ErrInvalidSignal is returned when a SpliceInfoSection does not conform to
+// ANSI/SCTE 35.
+var ErrInvalidSignal = errors.New("invalid splice_info_section")
+
+// NewTimeSignalBuilder returns a TimeSignalBuilder for a time_signal with the
+// given pts_time.
+//
+// The resulting SpliceInfoSection defaults to a tier of 0xFFF (all tiers),
+// sap_type 0x3 (Not Specified) and no encryption.
+func NewTimeSignalBuilder(ptsTime uint64) *TimeSignalBuilder {
+	return &TimeSignalBuilder{
+		sis: &SpliceInfoSection{
+			SpliceCommand:   NewTimeSignal(ptsTime),
+			EncryptedPacket: EncryptedPacket{EncryptionAlgorithm: EncryptionAlgorithmNone, CWIndex: 0xFF},
+			SAPType:         SAPTypeNotSpecified,
+			Tier:            maxTier,
+		},
+	}
+}
+
+// TimeSignalBuilder builds a time_signal SpliceInfoSection. Each method
+// returns the builder so calls may be chained; errors are collected and
+// returned by Build.
+//
+// WithSegmentation starts a new SegmentationDescriptor. Methods that modify a
+// segmentation_descriptor (ie, WithUPID) apply to the most recent
+// SegmentationDescriptor.
+type TimeSignalBuilder struct {
+	sis  *SpliceInfoSection
+	sd   *SegmentationDescriptor
+	errs []error
+}
+
+// WithTier sets the tier.
+func (b *TimeSignalBuilder) WithTier(tier uint32) *TimeSignalBuilder {
+	b.sis.Tier = tier
+	return b
+}
+
+// WithSAPType sets the sap_type.
+func (b *TimeSignalBuilder) WithSAPType(sapType uint32) *TimeSignalBuilder {
+	b.sis.SAPType = sapType
+	return b
+}
+
+// WithPTSAdjustment sets the pts_adjustment.
+func (b *TimeSignalBuilder) WithPTSAdjustment(ptsAdjustment uint64) *TimeSignalBuilder {
+	b.sis.PTSAdjustment = ptsAdjustment
+	return b
+}
+
+// WithSegmentation adds a SegmentationDescriptor with the given
+// segmentation_event_id and segmentation_type_id.
+//
+// segment_num and segments_expected default to 1 for segmentation_type_ids
+// where ANSI/SCTE 35 requires them to be 1 or non-zero (ie, Program Start),
+// and 0 otherwise.
+func (b *TimeSignalBuilder) WithSegmentation(eventID uint32, segmentationTypeID uint32) *TimeSignalBuilder {
+	b.sd = &SegmentationDescriptor{
+		SegmentationEventID: eventID,
+		SegmentationTypeID:  segmentationTypeID,
+	}
+	switch segmentNumRules(segmentationTypeID) {
+	case segmentNumOne, segmentNumNonZero:
+		b.sd.SegmentNum = 1
+		b.sd.SegmentsExpected = 1
+	}
+	b.sis.SpliceDescriptors = append(b.sis.SpliceDescriptors, b.sd)
+	return b
+}
+
+// WithDuration sets the segmentation_duration.
+func (b *TimeSignalBuilder) WithDuration(d time.Duration) *TimeSignalBuilder {
+	if b.segmentation("WithDuration") {
+		ticks := DurationToTicks(d)
+		b.sd.SegmentationDuration = &ticks
+	}
+	return b
+}
+
+// WithSegmentNum sets the segment_num and segments_expected.
+func (b *TimeSignalBuilder) WithSegmentNum(segmentNum, segmentsExpected uint32) *TimeSignalBuilder {
+	if b.segmentation("WithSegmentNum") {
+		b.sd.SegmentNum = segmentNum
+		b.sd.SegmentsExpected = segmentsExpected
+	}
+	return b
+}
+
+// WithSubSegmentNum sets the sub_segment_num and sub_segments_expected.
+func (b *TimeSignalBuilder) WithSubSegmentNum(subSegmentNum, subSegmentsExpected uint32) *TimeSignalBuilder {
+	if b.segmentation("WithSubSegmentNum") {
+		b.sd.SubSegmentNum = &subSegmentNum
+		b.sd.SubSegmentsExpected = &subSegmentsExpected
+	}
+	return b
+}
+
+// WithDeliveryRestrictions sets the delivery restrictions. Delivery is not
+// restricted unless WithDeliveryRestrictions is called.
+func (b *TimeSignalBuilder) WithDeliveryRestrictions(dr DeliveryRestrictions) *TimeSignalBuilder {
+	if b.segmentation("WithDeliveryRestrictions") {
+		b.sd.DeliveryRestrictions = &dr
+	}
+	return b
+}
+
+// WithUPID adds a SegmentationUPID. A MID() is signalled if more than one
+// SegmentationUPID is added.
+func (b *TimeSignalBuilder) WithUPID(upid SegmentationUPID) *TimeSignalBuilder {
+	if b.segmentation("WithUPID") {
+		b.sd.SegmentationUPIDs = append(b.sd.SegmentationUPIDs, upid)
+	}
+	return b
+}
+
+// WithCancel sets the segmentation_event_cancel_indicator.
+func (b *TimeSignalBuilder) WithCancel() *TimeSignalBuilder {
+	if b.segmentation("WithCancel") {
+		b.sd.SegmentationEventCancelIndicator = true
+	}
+	return b
+}
+
+// WithDescriptor adds a SpliceDescriptor.
+func (b *TimeSignalBuilder) WithDescriptor(sd SpliceDescriptor) *TimeSignalBuilder {
+	b.sis.SpliceDescriptors = append(b.sis.SpliceDescriptors, sd)
+	return b
+}
+
+// Build validates and returns the SpliceInfoSection. All errors are returned
+// together and wrap ErrInvalidSignal or ErrInvalidUPID. Each call returns a
+// new SpliceInfoSection, so the builder may continue to be used.
+func (b *TimeSignalBuilder) Build() (*SpliceInfoSection, error) {
+	errs := append([]error{}, b.errs...)
+
+	if pts := b.sis.SpliceCommand.(*TimeSignal).SpliceTime.PTSTime; *pts > maxPTS {
+		errs = append(errs, fmt.Errorf("%w: pts_time %d exceeds 33 bits", ErrInvalidSignal, *pts))
+	}
+	if b.sis.PTSAdjustment > maxPTS {
+		errs = append(errs, fmt.Errorf("%w: pts_adjustment %d exceeds 33 bits", ErrInvalidSignal, b.sis.PTSAdjustment))
+	}
+	if b.sis.Tier > maxTier {
+		errs = append(errs, fmt.Errorf("%w: tier %#x exceeds 12 bits", ErrInvalidSignal, b.sis.Tier))
+	}
+	if b.sis.SAPType > SAPTypeNotSpecified {
+		errs = append(errs, fmt.Errorf("%w: sap_type %#x exceeds 2 bits", ErrInvalidSignal, b.sis.SAPType))
+	}
+	for i, sd := range b.sis.SpliceDescriptors {
+		if sd, ok := sd.(*SegmentationDescriptor); ok {
+			for _, err := range validateSegmentationDescriptor(sd) {
+				errs = append(errs, fmt.Errorf("segmentation_descriptor[%d]: %w", i, err))
+			}
+		}
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+	return b.sis.Clone(), nil
+}
+
+// segmentation returns true if a SegmentationDescriptor has been started,
+// otherwise an error is recorded for the named method.
+func (b *TimeSignalBuilder) segmentation(method string) bool {
+	if b.sd == nil {
+		b.errs = append(b.errs, fmt.Errorf("%w: %s called before WithSegmentation", ErrInvalidSignal, method))
+		return false
+	}
+	return true
+}
+
+// segmentNumRule describes the permitted values of segment_num and
+// segments_expected for a segmentation_type_id, per Table 23.
+type segmentNumRule int
+
+const (
+	segmentNumAny segmentNumRule = iota
+	segmentNumZero
+	segmentNumOne
+	segmentNumNonZero
+)
+
+// segmentNumRules returns the segmentNumRule for a segmentation_type_id.
+func segmentNumRules(segmentationTypeID uint32) segmentNumRule {
+	switch segmentationTypeID {
+	case SegmentationTypeNotIndicated,
+		SegmentationTypeContentIdentification,
+		SegmentationTypeProgramBlackoutOverride,
+		SegmentationTypeUnscheduledEventStart,
+		SegmentationTypeUnscheduledEventEnd,
+		SegmentationTypeNetworkStart,
+		SegmentationTypeNetworkEnd:
+		return segmentNumZero
+	case SegmentationTypeProgramStart,
+		SegmentationTypeProgramEnd,
+		SegmentationTypeProgramEarlyTermination,
+		SegmentationTypeProgramBreakaway,
+		SegmentationTypeProgramResumption,
+		SegmentationTypeProgramRunoverPlanned,
+		SegmentationTypeProgramRunoverUnplanned,
+		SegmentationTypeProgramOverlapStart,
+		SegmentationTypeProgramStartInProgress,
+		SegmentationTypeOpeningCreditStart,
+		SegmentationTypeOpeningCreditEnd,
+		SegmentationTypeClosingCreditStart,
+		SegmentationTypeClosingCreditEnd:
+		return segmentNumOne
+	case SegmentationTypeChapterStart,
+		SegmentationTypeChapterEnd:
+		return segmentNumNonZero
+	default:
+		return segmentNumAny
+	}
+}
+
+// validateSegmentationDescriptor returns any errors in a
+// SegmentationDescriptor, including its SegmentationUPIDs.
+func validateSegmentationDescriptor(sd *SegmentationDescriptor) []error {
+	if sd.SegmentationEventCancelIndicator {
+		return nil
+	}
+
+	errs := validateSegmentationFields(sd)
+	valid := true
+	for i := range sd.SegmentationUPIDs {
+		if err := sd.SegmentationUPIDs[i].Validate(); err != nil {
+			errs = append(errs, err)
+			valid = false
+		}
+	}
+	// catch values that validate but cannot be encoded (ie, a TI that is not
+	// an integer)
+	if _, _, err := sd.segmentationUPID(); valid && err != nil {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// validateSegmentationFields returns any errors in the fields of a
+// SegmentationDescriptor that are constrained by its segmentation_type_id.
+// SegmentationUPID values are not checked.
+func validateSegmentationFields(sd *SegmentationDescriptor) []error {
+	var errs []error
+
+	if sd.SegmentationEventCancelIndicator {
+		return nil
+	}
+
+	switch segmentNumRules(sd.SegmentationTypeID) {
+	case segmentNumZero:
+		if sd.SegmentNum != 0 || sd.SegmentsExpected != 0 {
+			errs = append(errs, fmt.Errorf("%w: segment_num and segments_expected must be 0 for %s", ErrInvalidSignal, sd.Name()))
+		}
+	case segmentNumOne:
+		if sd.SegmentNum != 1 || sd.SegmentsExpected != 1 {
+			errs = append(errs, fmt.Errorf("%w: segment_num and segments_expected must be 1 for %s", ErrInvalidSignal, sd.Name()))
+		}
+	case segmentNumNonZero:
+		if sd.SegmentNum == 0 || sd.SegmentsExpected == 0 {
+			errs = append(errs, fmt.Errorf("%w: segment_num and segments_expected must be non-zero for %s", ErrInvalidSignal, sd.Name()))
+		}
+	}
+	if sd.SegmentNum > 0xFF || sd.SegmentsExpected > 0xFF {
+		errs = append(errs, fmt.Errorf("%w: segment_num and segments_expected must not exceed 8 bits", ErrInvalidSignal))
+	}
+	if sd.SegmentsExpected != 0 && sd.SegmentNum > sd.SegmentsExpected {
+		errs = append(errs, fmt.Errorf("%w: segment_num %d exceeds segments_expected %d", ErrInvalidSignal, sd.SegmentNum, sd.SegmentsExpected))
+	}
+
+	if sd.SubSegmentNum != nil || sd.SubSegmentsExpected != nil {
+		switch sd.SegmentationTypeID {
+		case SegmentationTypeProviderPOStart,
+			SegmentationTypeDistributorPOStart,
+			SegmentationTypeProviderOverlayPOStart,
+			SegmentationTypeDistributorOverlayPOStart:
+		default:
+			errs = append(errs, fmt.Errorf("%w: sub_segment_num is not used for %s", ErrInvalidSignal, sd.Name()))
+		}
+	}
+	if sd.SubSegmentNum != nil && sd.SubSegmentsExpected != nil && *sd.SubSegmentsExpected != 0 && *sd.SubSegmentNum > *sd.SubSegmentsExpected {
+		errs = append(errs, fmt.Errorf("%w: sub_segment_num %d exceeds sub_segments_expected %d", ErrInvalidSignal, *sd.SubSegmentNum, *sd.SubSegmentsExpected))
+	}
+
+	if sd.SegmentationDuration != nil && *sd.SegmentationDuration > maxSegmentationDuration {
+		errs = append(errs, fmt.Errorf("%w: segmentation_duration %d exceeds 40 bits", ErrInvalidSignal, *sd.SegmentationDuration))
+	}
+	if sd.DeliveryRestrictions != nil && sd.DeliveryRestrictions.DeviceRestrictions > DeviceRestrictionsNone {
+		errs = append(errs, fmt.Errorf("%w: device_restrictions %#x exceeds 2 bits", ErrInvalidSignal, sd.DeliveryRestrictions.DeviceRestrictions))
+	}
+
+	if sd.SegmentationTypeID == SegmentationTypeContentIdentification && len(sd.SegmentationUPIDs) == 0 {
+		errs = append(errs, fmt.Errorf("%w: segmentation_upid is required for %s", ErrInvalidSignal, sd.Name()))
+	}
+	return errs
+}
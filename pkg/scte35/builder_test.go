@@ -0,0 +1,172 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTimeSignalBuilder(t *testing.T) {
+	tiUPID := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeTI, Format: scte35.SegmentationUPIDFormatText, Value: "78511452"}
+
+	cases := map[string]struct {
+		builder  *scte35.TimeSignalBuilder
+		expected *scte35.SpliceInfoSection
+		err      error
+	}{
+		"Defaults": {
+			builder: scte35.NewTimeSignalBuilder(0x072bd0050).
+				WithSegmentation(1, scte35.SegmentationTypeProgramStart).
+				WithDuration(30 * time.Second).
+				WithUPID(tiUPID),
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x072bd0050),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID:  1,
+						SegmentationTypeID:   scte35.SegmentationTypeProgramStart,
+						SegmentationDuration: ptr(uint64(2700000)),
+						SegmentationUPIDs:    []scte35.SegmentationUPID{tiUPID},
+						SegmentNum:           1,
+						SegmentsExpected:     1,
+					},
+				},
+				EncryptedPacket: scte35.EncryptedPacket{CWIndex: 255},
+				SAPType:         scte35.SAPTypeNotSpecified,
+				Tier:            4095,
+			},
+		},
+		"Delivery Restrictions": {
+			builder: scte35.NewTimeSignalBuilder(0x072bd0050).
+				WithTier(0x100).
+				WithSAPType(scte35.SAPType1).
+				WithSegmentation(0x4800008e, scte35.SegmentationTypeProviderPOStart).
+				WithDeliveryRestrictions(scte35.DeliveryRestrictions{
+					NoRegionalBlackoutFlag: true,
+					ArchiveAllowedFlag:     true,
+					DeviceRestrictions:     scte35.DeviceRestrictionsNone,
+				}).
+				WithSegmentNum(2, 0).
+				WithSubSegmentNum(1, 2).
+				WithUPID(tiUPID),
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x072bd0050),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						DeliveryRestrictions: &scte35.DeliveryRestrictions{
+							NoRegionalBlackoutFlag: true,
+							ArchiveAllowedFlag:     true,
+							DeviceRestrictions:     scte35.DeviceRestrictionsNone,
+						},
+						SegmentationEventID: 0x4800008e,
+						SegmentationTypeID:  scte35.SegmentationTypeProviderPOStart,
+						SegmentationUPIDs:   []scte35.SegmentationUPID{tiUPID},
+						SegmentNum:          2,
+						SubSegmentNum:       ptr(uint32(1)),
+						SubSegmentsExpected: ptr(uint32(2)),
+					},
+				},
+				EncryptedPacket: scte35.EncryptedPacket{CWIndex: 255},
+				SAPType:         scte35.SAPType1,
+				Tier:            0x100,
+			},
+		},
+		"Invalid Tier": {
+			builder: scte35.NewTimeSignalBuilder(0).WithTier(0x1000),
+			err:     scte35.ErrInvalidSignal,
+		},
+		"Invalid PTS": {
+			builder: scte35.NewTimeSignalBuilder(1 << 33),
+			err:     scte35.ErrInvalidSignal,
+		},
+		"UPID Before Segmentation": {
+			builder: scte35.NewTimeSignalBuilder(0).WithUPID(tiUPID),
+			err:     scte35.ErrInvalidSignal,
+		},
+		"Invalid Segment Num": {
+			builder: scte35.NewTimeSignalBuilder(0).
+				WithSegmentation(1, scte35.SegmentationTypeProgramStart).
+				WithSegmentNum(2, 3),
+			err: scte35.ErrInvalidSignal,
+		},
+		"Invalid Sub Segment Num": {
+			builder: scte35.NewTimeSignalBuilder(0).
+				WithSegmentation(1, scte35.SegmentationTypeProgramStart).
+				WithSubSegmentNum(1, 1),
+			err: scte35.ErrInvalidSignal,
+		},
+		"Sub Segment Num Exceeds Sub Segments Expected": {
+			builder: scte35.NewTimeSignalBuilder(0).
+				WithSegmentation(1, scte35.SegmentationTypeProviderPOStart).
+				WithDuration(time.Minute).
+				WithSubSegmentNum(3, 2),
+			err: scte35.ErrInvalidSignal,
+		},
+		"Missing Content Identification UPID": {
+			builder: scte35.NewTimeSignalBuilder(0).
+				WithSegmentation(1, scte35.SegmentationTypeContentIdentification),
+			err: scte35.ErrInvalidSignal,
+		},
+		"Invalid UPID": {
+			builder: scte35.NewTimeSignalBuilder(0).
+				WithSegmentation(1, scte35.SegmentationTypeProgramStart).
+				WithUPID(scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Value: "ABC"}),
+			err: scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis, err := c.builder.Build()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, toJSON(c.expected), toJSON(sis))
+			require.Equal(t, c.expected.Base64(), sis.Base64())
+
+			// round trip
+			decoded, err := scte35.DecodeBase64(sis.Base64())
+			require.NoError(t, err)
+			require.Equal(t, toJSON(sis), toJSON(decoded))
+		})
+	}
+}
+
+func TestTimeSignalBuilder_Build(t *testing.T) {
+	builder := scte35.NewTimeSignalBuilder(0x072bd0050).
+		WithSegmentation(1, scte35.SegmentationTypeProgramStart).
+		WithDuration(30 * time.Second)
+	first, err := builder.Build()
+	require.NoError(t, err)
+	expected := first.Base64()
+
+	// sections that have been built are not modified by later calls
+	second, err := builder.Build()
+	require.NoError(t, err)
+	require.NotSame(t, first, second)
+	second.Tier = 1
+	second.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationEventID = 2
+
+	_, err = builder.WithTier(2).WithDuration(time.Minute).Build()
+	require.NoError(t, err)
+	require.Equal(t, expected, first.Base64())
+}
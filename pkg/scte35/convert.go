@@ -0,0 +1,157 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnsupportedConversion is returned when a SpliceInfoSection cannot be
+// converted between splice_insert and time_signal.
+var ErrUnsupportedConversion = errors.New("unsupported conversion")
+
+// SpliceInsertToTimeSignal converts a splice_insert SpliceInfoSection to a
+// time_signal with a segmentation_descriptor. segmentationTypeID selects the
+// descriptor pair and must be one of SegmentationTypeBreakStart,
+// SegmentationTypeProviderAdStart, SegmentationTypeDistributorAdStart,
+// SegmentationTypeProviderPOStart or SegmentationTypeDistributorPOStart. The
+// corresponding End type is used when out_of_network_indicator is 0.
+//
+// The fields are mapped as follows:
+//
+//	splice_event_id               -> segmentation_event_id
+//	splice_event_cancel_indicator -> segmentation_event_cancel_indicator
+//	out_of_network_indicator      -> segmentation_type_id (Start or End)
+//	splice_time()                 -> time_signal splice_time()
+//	break_duration() duration     -> segmentation_duration (Start only)
+//	avail_num                     -> segment_num
+//	avails_expected               -> segments_expected
+//
+// The segmentation_descriptor is inserted before any existing
+// splice_descriptors, which are retained. The conversion is lossy:
+// unique_program_id and auto_return are discarded, and a splice_immediate_flag
+// is represented as a time_signal without a pts_time. Component splice mode
+// cannot be represented by a time_signal and returns ErrUnsupportedConversion.
+func SpliceInsertToTimeSignal(sis *SpliceInfoSection, segmentationTypeID uint32) (*SpliceInfoSection, error) {
+	cmd, ok := sis.SpliceCommand.(*SpliceInsert)
+	if !ok {
+		return nil, fmt.Errorf("splice_insert: %w: splice_command is not a splice_insert", ErrUnsupportedConversion)
+	}
+	if !convertibleStart(segmentationTypeID) {
+		return nil, fmt.Errorf("splice_insert: %w: segmentation_type_id %#02x", ErrUnsupportedConversion, segmentationTypeID)
+	}
+	if !cmd.SpliceEventCancelIndicator && !cmd.ProgramSpliceFlag() {
+		return nil, fmt.Errorf("splice_insert: %w: component splice mode", ErrUnsupportedConversion)
+	}
+
+	out := sis.Clone()
+	cmd = out.SpliceCommand.(*SpliceInsert)
+
+	ts := &TimeSignal{}
+	sd := &SegmentationDescriptor{
+		SegmentationEventID:              cmd.SpliceEventID,
+		SegmentationEventCancelIndicator: cmd.SpliceEventCancelIndicator,
+	}
+	if !cmd.SpliceEventCancelIndicator {
+		if !cmd.SpliceImmediateFlag {
+			ts.SpliceTime = cmd.Program.SpliceTime
+		}
+		sd.SegmentationTypeID = segmentationTypeID
+		if !cmd.OutOfNetworkIndicator {
+			sd.SegmentationTypeID++
+		} else if cmd.BreakDuration != nil {
+			sd.SegmentationDuration = &cmd.BreakDuration.Duration
+		}
+		sd.SegmentNum = cmd.AvailNum
+		sd.SegmentsExpected = cmd.AvailsExpected
+	}
+
+	out.SpliceCommand = ts
+	out.SpliceDescriptors = append(SpliceDescriptors{sd}, out.SpliceDescriptors...)
+	return out, nil
+}
+
+// TimeSignalToSpliceInsert converts a time_signal SpliceInfoSection to a
+// splice_insert. It is the inverse of SpliceInsertToTimeSignal and uses the
+// first segmentation_descriptor with a cancel indicator or a supported Start or
+// End segmentation_type_id, which is removed from the splice_descriptors.
+//
+// The conversion is lossy: segmentation_upids, delivery restrictions and
+// sub-segments are discarded, auto_return is set to 1 and unique_program_id
+// is set to 0. A time_signal without a pts_time is converted to a
+// splice_insert with the splice_immediate_flag set.
+func TimeSignalToSpliceInsert(sis *SpliceInfoSection) (*SpliceInfoSection, error) {
+	if _, ok := sis.SpliceCommand.(*TimeSignal); !ok {
+		return nil, fmt.Errorf("time_signal: %w: splice_command is not a time_signal", ErrUnsupportedConversion)
+	}
+
+	i := -1
+	for j, d := range sis.SpliceDescriptors {
+		if sd, ok := d.(*SegmentationDescriptor); ok {
+			start := sd.SegmentationTypeID
+			if start%2 == 1 {
+				start--
+			}
+			if sd.SegmentationEventCancelIndicator || convertibleStart(start) {
+				i = j
+				break
+			}
+		}
+	}
+	if i < 0 {
+		return nil, fmt.Errorf("time_signal: %w: no supported segmentation_descriptor", ErrUnsupportedConversion)
+	}
+
+	out := sis.Clone()
+	ts := out.SpliceCommand.(*TimeSignal)
+	sd := out.SpliceDescriptors[i].(*SegmentationDescriptor)
+
+	cmd := &SpliceInsert{
+		SpliceEventID:              sd.SegmentationEventID,
+		SpliceEventCancelIndicator: sd.SegmentationEventCancelIndicator,
+	}
+	if !sd.SegmentationEventCancelIndicator {
+		cmd.OutOfNetworkIndicator = convertibleStart(sd.SegmentationTypeID)
+		cmd.Program = &SpliceInsertProgram{SpliceTime: ts.SpliceTime}
+		cmd.SpliceImmediateFlag = !ts.SpliceTime.TimeSpecifiedFlag()
+		if sd.SegmentationDuration != nil {
+			cmd.BreakDuration = &BreakDuration{AutoReturn: true, Duration: *sd.SegmentationDuration}
+		}
+		cmd.AvailNum = sd.SegmentNum
+		cmd.AvailsExpected = sd.SegmentsExpected
+	}
+
+	out.SpliceCommand = cmd
+	out.SpliceDescriptors = append(out.SpliceDescriptors[:i], out.SpliceDescriptors[i+1:]...)
+	return out, nil
+}
+
+// convertibleStart returns true if segmentationTypeID is a Start type that can
+// be converted to and from a splice_insert.
+func convertibleStart(segmentationTypeID uint32) bool {
+	switch segmentationTypeID {
+	case SegmentationTypeBreakStart,
+		SegmentationTypeProviderAdStart,
+		SegmentationTypeDistributorAdStart,
+		SegmentationTypeProviderPOStart,
+		SegmentationTypeDistributorPOStart:
+		return true
+	default:
+		return false
+	}
+}
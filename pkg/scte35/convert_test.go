@@ -0,0 +1,246 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35_test
+
+import (
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpliceInsertToTimeSignal(t *testing.T) {
+	cases := map[string]struct {
+		spliceInsert       *scte35.SpliceInsert
+		segmentationTypeID uint32
+		expected           *scte35.SpliceInfoSection
+		err                error
+		lossy              bool
+	}{
+		"Out Of Network": {
+			spliceInsert: &scte35.SpliceInsert{
+				SpliceEventID:         0x4800008f,
+				OutOfNetworkIndicator: true,
+				Program:               scte35.NewSpliceInsertProgram(0x07369c02e),
+				BreakDuration:         &scte35.BreakDuration{AutoReturn: true, Duration: 0x00052ccf5},
+				AvailNum:              1,
+				AvailsExpected:        2,
+			},
+			segmentationTypeID: scte35.SegmentationTypeBreakStart,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x07369c02e),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID:  0x4800008f,
+						SegmentationTypeID:   scte35.SegmentationTypeBreakStart,
+						SegmentationDuration: ptr(uint64(0x00052ccf5)),
+						SegmentNum:           1,
+						SegmentsExpected:     2,
+					},
+					&scte35.AvailDescriptor{ProviderAvailID: 309},
+				},
+			},
+		},
+		"In Network": {
+			spliceInsert: &scte35.SpliceInsert{
+				SpliceEventID: 0x4800008f,
+				Program:       scte35.NewSpliceInsertProgram(0x0735ec5a3),
+			},
+			segmentationTypeID: scte35.SegmentationTypeProviderAdStart,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x0735ec5a3),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 0x4800008f,
+						SegmentationTypeID:  scte35.SegmentationTypeProviderAdEnd,
+					},
+					&scte35.AvailDescriptor{ProviderAvailID: 309},
+				},
+			},
+		},
+		"Splice Immediate": {
+			spliceInsert: &scte35.SpliceInsert{
+				SpliceEventID:         1,
+				OutOfNetworkIndicator: true,
+				SpliceImmediateFlag:   true,
+				Program:               &scte35.SpliceInsertProgram{},
+			},
+			segmentationTypeID: scte35.SegmentationTypeDistributorAdStart,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.TimeSignal{},
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeDistributorAdStart,
+					},
+					&scte35.AvailDescriptor{ProviderAvailID: 309},
+				},
+			},
+		},
+		"Cancel": {
+			spliceInsert: &scte35.SpliceInsert{
+				SpliceEventID:              1,
+				SpliceEventCancelIndicator: true,
+			},
+			segmentationTypeID: scte35.SegmentationTypeBreakStart,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.TimeSignal{},
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID:              1,
+						SegmentationEventCancelIndicator: true,
+					},
+					&scte35.AvailDescriptor{ProviderAvailID: 309},
+				},
+			},
+		},
+		"Lossy": {
+			spliceInsert: &scte35.SpliceInsert{
+				SpliceEventID:         1,
+				OutOfNetworkIndicator: true,
+				Program:               scte35.NewSpliceInsertProgram(100),
+				BreakDuration:         &scte35.BreakDuration{Duration: 200},
+				UniqueProgramID:       3,
+			},
+			segmentationTypeID: scte35.SegmentationTypeProviderPOStart,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID:  1,
+						SegmentationTypeID:   scte35.SegmentationTypeProviderPOStart,
+						SegmentationDuration: ptr(uint64(200)),
+					},
+					&scte35.AvailDescriptor{ProviderAvailID: 309},
+				},
+			},
+			lossy: true,
+		},
+		"Component Splice Mode": {
+			spliceInsert: &scte35.SpliceInsert{
+				OutOfNetworkIndicator: true,
+				Components:            []scte35.SpliceInsertComponent{{Tag: 1}},
+			},
+			segmentationTypeID: scte35.SegmentationTypeBreakStart,
+			err:                scte35.ErrUnsupportedConversion,
+		},
+		"Unsupported Segmentation Type": {
+			spliceInsert: &scte35.SpliceInsert{
+				Program: scte35.NewSpliceInsertProgram(100),
+			},
+			segmentationTypeID: scte35.SegmentationTypeBreakEnd,
+			err:                scte35.ErrUnsupportedConversion,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis := &scte35.SpliceInfoSection{
+				SpliceCommand:     c.spliceInsert,
+				SpliceDescriptors: scte35.SpliceDescriptors{&scte35.AvailDescriptor{ProviderAvailID: 309}},
+			}
+			converted, err := scte35.SpliceInsertToTimeSignal(sis, c.segmentationTypeID)
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Empty(t, scte35.Diff(c.expected, converted))
+
+			// and back again
+			reverted, err := scte35.TimeSignalToSpliceInsert(converted)
+			require.NoError(t, err)
+			require.Equal(t, !c.lossy, scte35.Equal(sis, reverted), scte35.Diff(sis, reverted))
+		})
+	}
+}
+
+func TestTimeSignalToSpliceInsert(t *testing.T) {
+	cases := map[string]struct {
+		sis      *scte35.SpliceInfoSection
+		expected *scte35.SpliceInfoSection
+		err      error
+	}{
+		"Segmentation UPID": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeProgramStart,
+					},
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID:  2,
+						SegmentationTypeID:   scte35.SegmentationTypeProviderAdStart,
+						SegmentationDuration: ptr(uint64(300)),
+						SegmentationUPIDs: []scte35.SegmentationUPID{
+							{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD0001000H"},
+						},
+						SegmentNum:       1,
+						SegmentsExpected: 1,
+					},
+				},
+			},
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.SpliceInsert{
+					SpliceEventID:         2,
+					OutOfNetworkIndicator: true,
+					Program:               scte35.NewSpliceInsertProgram(100),
+					BreakDuration:         &scte35.BreakDuration{AutoReturn: true, Duration: 300},
+					AvailNum:              1,
+					AvailsExpected:        1,
+				},
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeProgramStart,
+					},
+				},
+			},
+		},
+		"No Segmentation Descriptor": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeProgramStart,
+					},
+				},
+			},
+			err: scte35.ErrUnsupportedConversion,
+		},
+		"Splice Insert": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.SpliceInsert{},
+			},
+			err: scte35.ErrUnsupportedConversion,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			original := c.sis.Clone()
+			converted, err := scte35.TimeSignalToSpliceInsert(c.sis)
+			require.ErrorIs(t, err, c.err)
+			require.True(t, scte35.Equal(original, c.sis))
+			if err != nil {
+				return
+			}
+			require.Empty(t, scte35.Diff(c.expected, converted))
+		})
+	}
+}
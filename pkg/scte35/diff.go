@@ -0,0 +1,227 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Difference is a single field-level difference between two
+// SpliceInfoSections.
+type Difference struct {
+	// Path is the location of the field, using the JSON field names (ie,
+	// spliceDescriptors[0].segmentationUpids[1].value).
+	Path string
+	// A is the value in the first SpliceInfoSection, or nil if absent.
+	A interface{}
+	// B is the value in the second SpliceInfoSection, or nil if absent.
+	B interface{}
+}
+
+// String returns a human-readable description of this Difference.
+func (d Difference) String() string {
+	a, b := d.ValueStrings()
+	return fmt.Sprintf("%s: %s != %s", d.Path, a, b)
+}
+
+// ValueStrings returns the textual representations of A and B, as used by
+// String.
+func (d Difference) ValueStrings() (string, string) {
+	return diffValueString(d.A), diffValueString(d.B)
+}
+
+// Equal returns true if a and b describe the same splice_info_section.
+// Decoding artifacts (such as alignment_stuffing and the CRC_32) are not
+// compared and nil slices are considered equal to empty slices.
+func Equal(a, b *SpliceInfoSection) bool {
+	return len(Diff(a, b)) == 0
+}
+
+// Diff returns the field-level differences between a and b, in the order the
+// fields are encoded. An empty result indicates the two are Equal.
+func Diff(a, b *SpliceInfoSection) []Difference {
+	if a == nil || b == nil {
+		if a == b {
+			return nil
+		}
+		return []Difference{{A: diffValue(reflect.ValueOf(a)), B: diffValue(reflect.ValueOf(b))}}
+	}
+
+	var diffs []Difference
+	diffs = diffUint("sapType", a.SAPType, b.SAPType, diffs)
+	diffs = diffUint("protocolVersion", a.ProtocolVersion, b.ProtocolVersion, diffs)
+	diffs = diffValues("encryptedPacket", reflect.ValueOf(a.EncryptedPacket), reflect.ValueOf(b.EncryptedPacket), diffs)
+	diffs = diffUint("ptsAdjustment", a.PTSAdjustment, b.PTSAdjustment, diffs)
+	diffs = diffUint("tier", a.Tier, b.Tier, diffs)
+	diffs = diffValues("spliceCommand", reflect.ValueOf(&a.SpliceCommand).Elem(), reflect.ValueOf(&b.SpliceCommand).Elem(), diffs)
+	diffs = diffValues("spliceDescriptors", reflect.ValueOf(a.SpliceDescriptors), reflect.ValueOf(b.SpliceDescriptors), diffs)
+	diffs = diffUint("preRollMilliSeconds", a.PreRollMilliSeconds, b.PreRollMilliSeconds, diffs)
+	return diffs
+}
+
+// DiffEncoding returns the differences between the decoding artifacts of a and
+// b that are not compared by Diff: the legacy splice_command_length indicator,
+// alignment_stuffing, E_CRC_32 and CRC_32. SpliceInfoSections that were not
+// decoded have no artifacts.
+func DiffEncoding(a, b *SpliceInfoSection) []Difference {
+	if a == nil || b == nil {
+		return nil
+	}
+
+	var diffs []Difference
+	if a.legacy != b.legacy {
+		diffs = append(diffs, Difference{Path: "legacySpliceCommandLength", A: a.legacy, B: b.legacy})
+	}
+	diffs = diffValues("alignmentStuffing", reflect.ValueOf(a.alignmentStuffing), reflect.ValueOf(b.alignmentStuffing), diffs)
+	diffs = diffValues("eCrc32", reflect.ValueOf(a.ecrc32), reflect.ValueOf(b.ecrc32), diffs)
+	diffs = diffValues("crc32", reflect.ValueOf(a.crc32), reflect.ValueOf(b.crc32), diffs)
+	return diffs
+}
+
+// diffUint appends a Difference if a and b are not equal.
+func diffUint[T uint32 | uint64](path string, a, b T, diffs []Difference) []Difference {
+	if a != b {
+		diffs = append(diffs, Difference{Path: path, A: a, B: b})
+	}
+	return diffs
+}
+
+// diffValues recursively compares a and b, which must be of the same type,
+// appending any differences.
+func diffValues(path string, a, b reflect.Value, diffs []Difference) []Difference {
+	switch a.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+			return diffs
+		case a.IsNil() || b.IsNil(), a.Elem().Type() != b.Elem().Type():
+			return append(diffs, Difference{Path: path, A: diffValue(a), B: diffValue(b)})
+		}
+		return diffValues(path, a.Elem(), b.Elem(), diffs)
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, ok := diffFieldName(t.Field(i))
+			if !ok {
+				continue
+			}
+			diffs = diffValues(path+"."+name, a.Field(i), b.Field(i), diffs)
+		}
+		return diffs
+	case reflect.Slice, reflect.Array:
+		if a.Type().Elem().Kind() == reflect.Uint8 {
+			if !bytes.Equal(diffBytes(a), diffBytes(b)) {
+				diffs = append(diffs, Difference{Path: path, A: diffValue(a), B: diffValue(b)})
+			}
+			return diffs
+		}
+		for i := 0; i < a.Len() || i < b.Len(); i++ {
+			p := path + "[" + strconv.Itoa(i) + "]"
+			switch {
+			case i >= a.Len():
+				diffs = append(diffs, Difference{Path: p, B: diffValue(b.Index(i))})
+			case i >= b.Len():
+				diffs = append(diffs, Difference{Path: p, A: diffValue(a.Index(i))})
+			default:
+				diffs = diffValues(p, a.Index(i), b.Index(i), diffs)
+			}
+		}
+		return diffs
+	default:
+		if a.Interface() != b.Interface() {
+			diffs = append(diffs, Difference{Path: path, A: a.Interface(), B: b.Interface()})
+		}
+		return diffs
+	}
+}
+
+// diffFieldName returns the JSON name of a struct field, or false if the
+// field is not compared.
+func diffFieldName(f reflect.StructField) (string, bool) {
+	// JSONType is a cache populated by Type() and Tag(); XMLName is
+	// populated by the XML decoder.
+	if !f.IsExported() || f.Name == "JSONType" || f.Type == reflect.TypeOf(xml.Name{}) {
+		return "", false
+	}
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return f.Name, true
+	default:
+		return name, true
+	}
+}
+
+// diffBytes returns the contents of a byte slice or array.
+func diffBytes(v reflect.Value) []byte {
+	if v.Kind() == reflect.Slice {
+		return v.Bytes()
+	}
+	b := make([]byte, v.Len())
+	reflect.Copy(reflect.ValueOf(b), v)
+	return b
+}
+
+// diffValue returns the value held by v, dereferencing pointers, or nil if
+// v is nil.
+func diffValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		// keep splice_commands and splice_descriptors as pointers so they
+		// can be identified in Difference.String().
+		if _, ok := v.Interface().(SpliceCommand); ok {
+			return v.Interface()
+		}
+		if _, ok := v.Interface().(SpliceDescriptor); ok {
+			return v.Interface()
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// diffValueString returns the textual representation of a Difference value.
+// Structs are rendered in their JSON form so the output uses the same field
+// names as Difference.Path.
+func diffValueString(v interface{}) string {
+	switch vt := v.(type) {
+	case nil:
+		return "<nil>"
+	case SpliceCommand, SpliceDescriptor:
+		return reflect.TypeOf(vt).Elem().Name()
+	case []byte:
+		return fmt.Sprintf("0x%X", vt)
+	case string:
+		return strconv.Quote(vt)
+	}
+	if reflect.TypeOf(v).Kind() == reflect.Struct {
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return fmt.Sprintf("%v", v)
+}
@@ -0,0 +1,156 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiff(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+
+	cases := map[string]struct {
+		mutate   func(sis *scte35.SpliceInfoSection)
+		expected []string
+	}{
+		"Equal": {
+			mutate: func(sis *scte35.SpliceInfoSection) {},
+		},
+		"Equal Empty Components": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).Components = []scte35.SegmentationDescriptorComponent{}
+			},
+		},
+		"Tier": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.Tier = 0x100
+			},
+			expected: []string{"tier: 4095 != 256"},
+		},
+		"PTS Time": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.SpliceCommand = scte35.NewTimeSignal(0x100)
+			},
+			expected: []string{"spliceCommand.spliceTime.ptsTime: 1924989008 != 256"},
+		},
+		"Splice Command Type": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.SpliceCommand = &scte35.SpliceNull{}
+			},
+			expected: []string{"spliceCommand: TimeSignal != SpliceNull"},
+		},
+		"Segmentation Duration": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationDuration = nil
+			},
+			expected: []string{"spliceDescriptors[0].segmentationDuration: 27630000 != <nil>"},
+		},
+		"Segmentation UPID": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sd := sis.SpliceDescriptors[0].(*scte35.SegmentationDescriptor)
+				sd.SegmentationUPIDs = append(sd.SegmentationUPIDs, scte35.SegmentationUPID{
+					Type:   scte35.SegmentationUPIDTypeAdID,
+					Format: scte35.SegmentationUPIDFormatText,
+					Value:  "ABCD0001000H",
+				})
+				sd.SegmentationUPIDs[0].Value = "1"
+			},
+			expected: []string{
+				`spliceDescriptors[0].segmentationUpids[0].value: "748724618" != "1"`,
+				`spliceDescriptors[0].segmentationUpids[1]: <nil> != {"segmentationUpidType":3,"segmentationUpidFormat":"text","value":"ABCD0001000H"}`,
+			},
+		},
+		"Segmentation Descriptor Component": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sd := sis.SpliceDescriptors[0].(*scte35.SegmentationDescriptor)
+				sd.Components = append(sd.Components, scte35.SegmentationDescriptorComponent{Tag: 1, PTSOffset: 2})
+			},
+			expected: []string{
+				`spliceDescriptors[0].components[0]: <nil> != {"componentTag":1,"ptsOffset":2}`,
+			},
+		},
+		"Splice Descriptors": {
+			mutate: func(sis *scte35.SpliceInfoSection) {
+				sis.SpliceDescriptors = append(sis.SpliceDescriptors, &scte35.AvailDescriptor{ProviderAvailID: 1})
+			},
+			expected: []string{"spliceDescriptors[1]: <nil> != AvailDescriptor"},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			a, err := scte35.DecodeBase64(signal)
+			require.NoError(t, err)
+			b, err := scte35.DecodeBase64(signal)
+			require.NoError(t, err)
+
+			c.mutate(b)
+
+			diffs := scte35.Diff(a, b)
+			actual := make([]string, 0, len(diffs))
+			for _, d := range diffs {
+				actual = append(actual, d.String())
+			}
+			require.ElementsMatch(t, c.expected, actual)
+			require.Equal(t, len(c.expected) == 0, scte35.Equal(a, b))
+		})
+	}
+}
+
+func TestEqual_JSON(t *testing.T) {
+	// Sample 14.2 splice_insert
+	sis, err := scte35.DecodeBase64("/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+	require.NoError(t, err)
+
+	b, err := json.Marshal(sis)
+	require.NoError(t, err)
+
+	var decoded scte35.SpliceInfoSection
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Empty(t, scte35.Diff(sis, &decoded))
+	require.True(t, scte35.Equal(sis, &decoded))
+
+	require.True(t, scte35.Equal(nil, nil))
+	require.False(t, scte35.Equal(sis, nil))
+}
+
+func TestDiffEncoding(t *testing.T) {
+	// Sample 14.2 splice_insert
+	a, err := scte35.DecodeBase64("/DAvAAAAAAAA///wFAVIAACPf+/+c2nALv4AUsz1AAAAAAAKAAhDVUVJAAABNWLbowo=")
+	require.NoError(t, err)
+
+	// legacy splice_command_length
+	b, err := scte35.DecodeBase64("/DA8AAAAAAAAAP///wb+06ACpQAmAiRDVUVJAACcHX//AACky4AMEERJU0NZTVdGMDQ1MjAwMEgxAQEMm4c0")
+	require.NoError(t, err)
+
+	require.Empty(t, scte35.DiffEncoding(a, a.Clone()))
+
+	diffs := scte35.DiffEncoding(a, b)
+	actual := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		actual = append(actual, d.String())
+	}
+	require.Equal(t, []string{
+		"legacySpliceCommandLength: false != true",
+		"crc32: 0x62DBA30A != 0x0C9B8734",
+	}, actual)
+}
@@ -42,15 +42,23 @@ type DTMFDescriptor struct {
 // writeTo the given table.
 func (sd *DTMFDescriptor) writeTo(t *table) {
 	t.row(0, "dtmf_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", DTMFDescriptorTag))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x (%s)", CUEIdentifier, CUEIASCII))
-	t.row(1, "preroll", float32(sd.Preroll/10))
-	t.row(1, "dtmf_count", len(sd.DTMFChars))
-	t.row(1, "dtmf_chars", sd.DTMFChars)
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", DTMFDescriptorTag), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x (%s)", CUEIdentifier, CUEIASCII), 32)
+	t.field(1, "preroll", float32(sd.Preroll/10), 8)
+	t.field(1, "dtmf_count", len(sd.DTMFChars), 3)
+	t.reserved(1, 5)
+	t.field(1, "dtmf_chars", sd.DTMFChars, len(sd.DTMFChars)*8)
+	t.end(1)
 	t.row(0, "}", nil)
 }
 
+// Clone returns a deep copy of this DTMFDescriptor.
+func (sd *DTMFDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	return &c
+}
+
 // Tag returns the splice_descriptor_tag.
 func (sd *DTMFDescriptor) Tag() uint32 {
 	// ensure JSONType is set
@@ -0,0 +1,179 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bamiaux/iobit"
+)
+
+const (
+	// EIDRLength is the length of a compact binary EIDR, in bytes.
+	EIDRLength = 12
+
+	// EIDRPrefixPartyID is the DOI registrant prefix for EIDR Party IDs.
+	EIDRPrefixPartyID = 5237
+	// EIDRPrefixUserID is the DOI registrant prefix for EIDR User IDs.
+	EIDRPrefixUserID = 5238
+	// EIDRPrefixServiceID is the DOI registrant prefix for EIDR Video Service
+	// IDs.
+	EIDRPrefixServiceID = 5239
+	// EIDRPrefixContentID is the DOI registrant prefix for EIDR Content IDs.
+	EIDRPrefixContentID = 5240
+)
+
+// DecodeEIDR decodes a compact binary EIDR, as defined in section 2.1.1 of
+// the EIDR ID Format.
+func DecodeEIDR(b []byte) (*EIDR, error) {
+	if len(b) != EIDRLength {
+		return nil, fmt.Errorf("eidr: %w: expected %d bytes, got %d", ErrInvalidUPID, EIDRLength, len(b))
+	}
+
+	r := iobit.NewReader(b)
+	e := &EIDR{Prefix: r.Uint32(16)}
+	copy(e.Suffix[:], r.LeftBytes())
+	if err := e.validatePrefix(); err != nil {
+		return e, err
+	}
+	return e, nil
+}
+
+// ParseEIDR parses an EIDR from its textual representation (ie,
+// 10.5240/0E4F-892E-442F-6BD4-15B0-1). The registrant prefix must belong to a
+// known EIDR registry and, if present, the trailing check character must be
+// valid. Shorter suffixes such as those used by Video Service IDs (ie,
+// 10.5239/C370-DCA5) are zero padded.
+func ParseEIDR(s string) (*EIDR, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	doi, suffix, ok := strings.Cut(s, "/")
+	if !ok || !strings.HasPrefix(doi, "10.") {
+		return nil, fmt.Errorf("eidr: %w: unrecognized format %q", ErrInvalidUPID, s)
+	}
+
+	prefix, err := strconv.ParseUint(strings.TrimPrefix(doi, "10."), 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("eidr: %w: non-canonical prefix %q", ErrInvalidUPID, doi)
+	}
+	e := &EIDR{Prefix: uint32(prefix)}
+
+	// split off the check character, if present
+	parts := strings.Split(suffix, "-")
+	var check string
+	if n := len(parts); n > 1 && len(parts[n-1]) == 1 {
+		check = parts[n-1]
+		parts = parts[:n-1]
+	}
+
+	h, err := hex.DecodeString(strings.Join(parts, ""))
+	if err != nil || len(h) > len(e.Suffix) {
+		return nil, fmt.Errorf("eidr: %w: non-canonical suffix %q", ErrInvalidUPID, suffix)
+	}
+	copy(e.Suffix[:], h)
+
+	if check != "" && check != e.CheckCharacter() {
+		return nil, fmt.Errorf("eidr: %w: invalid check character %q, expected %q", ErrInvalidUPID, check, e.CheckCharacter())
+	}
+	if err := e.validatePrefix(); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// EIDR is a structured Entertainment Identifier Registry identifier
+// (segmentation_upid_type 0x0A).
+type EIDR struct {
+	// Prefix is the DOI registrant prefix (ie, 5240 for 10.5240).
+	Prefix uint32
+	// Suffix is the 80-bit DOI suffix.
+	Suffix [10]byte
+}
+
+// Bytes returns the compact binary representation of this EIDR.
+func (e *EIDR) Bytes() []byte {
+	buf := make([]byte, EIDRLength)
+	iow := iobit.NewWriter(buf)
+	iow.PutUint32(16, e.Prefix)
+	_, _ = iow.Write(e.Suffix[:])
+	_ = iow.Flush()
+	return buf
+}
+
+// CheckCharacter returns the ISO 7064 MOD 37,36 check character for this
+// EIDR.
+func (e *EIDR) CheckCharacter() string {
+	return checkCharacterMod3736(fmt.Sprintf("%X", e.Suffix))
+}
+
+// SegmentationUPID returns this EIDR as a SegmentationUPID.
+func (e *EIDR) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeEIDR,
+		Format: SegmentationUPIDFormatText,
+		Value:  e.String(),
+	}
+}
+
+// String returns the textual representation of this EIDR, as carried in
+// SegmentationUPID.Value. The check character is not included.
+func (e *EIDR) String() string {
+	b := e.Suffix
+	return fmt.Sprintf("10.%d/%X-%X-%X-%X-%X", e.Prefix, b[0:2], b[2:4], b[4:6], b[6:8], b[8:10])
+}
+
+// TypeName returns the name of the EIDR registry for this EIDR's prefix.
+func (e *EIDR) TypeName() string {
+	switch e.Prefix {
+	case EIDRPrefixPartyID:
+		return "Party ID"
+	case EIDRPrefixUserID:
+		return "User ID"
+	case EIDRPrefixServiceID:
+		return "Service ID"
+	case EIDRPrefixContentID:
+		return "Content ID"
+	default:
+		return ""
+	}
+}
+
+// validatePrefix returns an error if the prefix does not belong to a known
+// EIDR registry.
+func (e *EIDR) validatePrefix() error {
+	if e.TypeName() == "" {
+		return fmt.Errorf("eidr: %w: unknown registrant prefix 10.%d", ErrInvalidUPID, e.Prefix)
+	}
+	return nil
+}
+
+// EIDR returns the Value of an EIDR SegmentationUPID as an EIDR.
+func (upid *SegmentationUPID) EIDR() (*EIDR, error) {
+	if upid.Type != SegmentationUPIDTypeEIDR {
+		return nil, fmt.Errorf("eidr: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	if upid.Format == SegmentationUPIDFormatBase64 {
+		b, err := upid.base64Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("eidr: %w", err)
+		}
+		return DecodeEIDR(b)
+	}
+	return ParseEIDR(upid.Value)
+}
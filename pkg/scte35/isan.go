@@ -0,0 +1,194 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bamiaux/iobit"
+)
+
+const (
+	// ISANLength is the length of a versioned ISAN, in bytes.
+	ISANLength = 12
+	// ISANDeprecatedLength is the length of an unversioned ISAN, in bytes.
+	ISANDeprecatedLength = 8
+
+	// mod3736Alphabet is the ISO 7064 MOD 37,36 character set.
+	mod3736Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+)
+
+// DecodeISAN decodes a binary ISO 15706 ISAN. Both the 12 byte (versioned)
+// and 8 byte (unversioned) encodings are supported.
+func DecodeISAN(b []byte) (*ISAN, error) {
+	if len(b) != ISANLength && len(b) != ISANDeprecatedLength {
+		return nil, fmt.Errorf("isan: %w: expected %d or %d bytes, got %d", ErrInvalidUPID, ISANLength, ISANDeprecatedLength, len(b))
+	}
+
+	r := iobit.NewReader(b)
+	isan := &ISAN{}
+	isan.Root = r.Uint64(48)
+	isan.Episode = r.Uint32(16)
+	if len(b) == ISANLength {
+		v := r.Uint32(32)
+		isan.Version = &v
+	}
+	return isan, nil
+}
+
+// ParseISAN parses an ISAN from its canonical textual representation, with
+// or without the leading "ISAN" label (ie, 0000-0000-3A8D-0000-Z-0000-0000-6).
+// Check characters are verified.
+func ParseISAN(s string) (*ISAN, error) {
+	s = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(s), "ISAN"))
+	parts := strings.Split(strings.ToUpper(s), "-")
+	if len(parts) != 5 && len(parts) != 8 {
+		return nil, fmt.Errorf("isan: %w: unrecognized format %q", ErrInvalidUPID, s)
+	}
+
+	hexParts := append([]string{}, parts[:4]...)
+	if len(parts) == 8 {
+		hexParts = append(hexParts, parts[5:7]...)
+	}
+	for _, p := range hexParts {
+		if len(p) != 4 {
+			return nil, fmt.Errorf("isan: %w: unrecognized format %q", ErrInvalidUPID, s)
+		}
+	}
+
+	// re-use the binary decoder to avoid duplicating the field layout
+	b := make([]byte, 0, ISANLength)
+	for _, p := range hexParts {
+		i, err := strconv.ParseUint(p, 16, 16)
+		if err != nil {
+			return nil, fmt.Errorf("isan: %w: %s", ErrInvalidUPID, err)
+		}
+		b = append(b, byte(i>>8), byte(i))
+	}
+	isan, err := DecodeISAN(b)
+	if err != nil {
+		return nil, err
+	}
+
+	// verify check characters
+	if c := isan.checkCharacters(); c[0] != parts[4] || (len(parts) == 8 && c[1] != parts[7]) {
+		return nil, fmt.Errorf("isan: %w: invalid check character(s) in %q", ErrInvalidUPID, s)
+	}
+	return isan, nil
+}
+
+// ISAN is a structured ISO 15706 International Standard Audiovisual Number
+// (segmentation_upid_type 0x05 and 0x06).
+type ISAN struct {
+	// Root is the 48-bit root segment.
+	Root uint64
+	// Episode is the 16-bit episode or part segment.
+	Episode uint32
+	// Version is the 32-bit version segment. Version is nil for unversioned
+	// (deprecated) ISANs.
+	Version *uint32
+}
+
+// Bytes returns the binary representation of this ISAN.
+func (isan *ISAN) Bytes() []byte {
+	length := ISANDeprecatedLength
+	if isan.Version != nil {
+		length = ISANLength
+	}
+
+	buf := make([]byte, length)
+	iow := iobit.NewWriter(buf)
+	iow.PutUint64(48, isan.Root)
+	iow.PutUint32(16, isan.Episode)
+	if isan.Version != nil {
+		iow.PutUint32(32, *isan.Version)
+	}
+	_ = iow.Flush()
+	return buf
+}
+
+// SegmentationUPID returns this ISAN as a SegmentationUPID.
+func (isan *ISAN) SegmentationUPID() SegmentationUPID {
+	upidType := uint32(SegmentationUPIDTypeISAN)
+	if isan.Version == nil {
+		upidType = SegmentationUPIDTypeISANDeprecated
+	}
+	return SegmentationUPID{
+		Type:   upidType,
+		Format: SegmentationUPIDFormatText,
+		Value:  isan.String(),
+	}
+}
+
+// String returns the canonical textual representation of this ISAN, including
+// check characters.
+func (isan *ISAN) String() string {
+	h := fmt.Sprintf("%012X%04X", isan.Root, isan.Episode)
+	c := isan.checkCharacters()
+	s := fmt.Sprintf("%s-%s-%s-%s-%s", h[0:4], h[4:8], h[8:12], h[12:16], c[0])
+	if isan.Version != nil {
+		v := fmt.Sprintf("%08X", *isan.Version)
+		s += fmt.Sprintf("-%s-%s-%s", v[0:4], v[4:8], c[1])
+	}
+	return s
+}
+
+// checkCharacters returns the check characters for the root + episode and
+// root + episode + version segments.
+func (isan *ISAN) checkCharacters() [2]string {
+	h := fmt.Sprintf("%012X%04X", isan.Root, isan.Episode)
+	c := [2]string{checkCharacterMod3736(h)}
+	if isan.Version != nil {
+		c[1] = checkCharacterMod3736(h + fmt.Sprintf("%08X", *isan.Version))
+	}
+	return c
+}
+
+// ISAN returns the Value of an ISAN SegmentationUPID as an ISAN.
+func (upid *SegmentationUPID) ISAN() (*ISAN, error) {
+	switch upid.Type {
+	case SegmentationUPIDTypeISAN, SegmentationUPIDTypeISANDeprecated:
+	default:
+		return nil, fmt.Errorf("isan: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	if upid.Format == SegmentationUPIDFormatBase64 {
+		b, err := upid.base64Bytes()
+		if err != nil {
+			return nil, fmt.Errorf("isan: %w", err)
+		}
+		return DecodeISAN(b)
+	}
+	return ParseISAN(upid.Value)
+}
+
+// checkCharacterMod3736 returns the ISO 7064 MOD 37,36 check character for the
+// given alphanumeric string.
+func checkCharacterMod3736(s string) string {
+	const m = 36
+	p := m
+	for _, r := range s {
+		v := strings.IndexRune(mod3736Alphabet, r)
+		p = (p + v) % m
+		if p == 0 {
+			p = m
+		}
+		p = (p * 2) % (m + 1)
+	}
+	return string(mod3736Alphabet[(m+1-p)%m])
+}
@@ -0,0 +1,178 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bamiaux/iobit"
+)
+
+// DecodeMID decodes a binary MID() structure. If the structure is truncated,
+// the UPIDs read so far (including the truncated UPID) are returned alongside
+// an error.
+func DecodeMID(b []byte) (MID, error) {
+	r := iobit.NewReader(b)
+	mid := MID{}
+	for r.LeftBits() > 0 {
+		upidType := r.Uint32(8)
+		upidLength := int(r.Uint32(8))
+		upidValue := r.Bytes(upidLength)
+		mid = append(mid, NewSegmentationUPID(upidType, upidValue))
+		if len(upidValue) < upidLength {
+			return mid, fmt.Errorf("mid: %w: cannot read value for segmentation_upid_type %d; %d of %d bytes remaining", ErrInvalidUPID, upidType, len(upidValue), upidLength)
+		}
+	}
+	return mid, nil
+}
+
+// MID is a structured multiple UPID (segmentation_upid_type 0x0D), containing
+// two or more SegmentationUPIDs.
+//
+// SegmentationDescriptor.SegmentationUPIDs holds the contained UPIDs directly,
+// and an MID() is signalled whenever more than one SegmentationUPID is
+// present. Use SegmentationDescriptor.MID to work with them as a MID. In JSON
+// the contained UPIDs are nested within a single MID() SegmentationUPID; XML
+// lists them as sibling SegmentationUpid elements, as in the SCTE 35 schema.
+type MID []SegmentationUPID
+
+// Add appends one or more SegmentationUPIDs to this MID.
+func (mid *MID) Add(upids ...SegmentationUPID) {
+	*mid = append(*mid, upids...)
+}
+
+// Bytes returns the binary representation of this MID.
+func (mid MID) Bytes() ([]byte, error) {
+	buf := make([]byte, 0, mid.length())
+	for _, upid := range mid {
+		vb, err := upid.valueBytes()
+		if err != nil {
+			return nil, fmt.Errorf("mid: %w", err)
+		}
+		if len(vb) > 0xFF {
+			return nil, fmt.Errorf("mid: %w: segmentation_upid_type %d value exceeds 255 bytes", ErrInvalidUPID, upid.Type)
+		}
+		buf = append(buf, byte(upid.Type), byte(len(vb)))
+		buf = append(buf, vb...)
+	}
+	return buf, nil
+}
+
+// Find returns the first SegmentationUPID of the given type.
+func (mid MID) Find(upidType uint32) (SegmentationUPID, bool) {
+	for _, upid := range mid {
+		if upid.Type == upidType {
+			return upid, true
+		}
+	}
+	return SegmentationUPID{}, false
+}
+
+// FindAll returns all SegmentationUPIDs of the given type, in order.
+func (mid MID) FindAll(upidType uint32) MID {
+	var found MID
+	for _, upid := range mid {
+		if upid.Type == upidType {
+			found = append(found, upid)
+		}
+	}
+	return found
+}
+
+// SegmentationUPID returns this MID as a single MID() SegmentationUPID, with
+// the binary MID() structure as its base-64 Value. An error is returned if any
+// of the contained SegmentationUPIDs cannot be encoded.
+func (mid MID) SegmentationUPID() (SegmentationUPID, error) {
+	b, err := mid.Bytes()
+	if err != nil {
+		return SegmentationUPID{}, err
+	}
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeMID,
+		Format: SegmentationUPIDFormatBase64,
+		Value:  base64.StdEncoding.EncodeToString(b),
+	}, nil
+}
+
+// length returns the expected length of the encoded MID, in bytes.
+func (mid MID) length() int {
+	length := 0
+	for _, upid := range mid {
+		vb, _ := upid.valueBytes()
+		length += 2 + len(vb) // segmentation_upid_type, segmentation_upid_length & segmentation_upid
+	}
+	return length
+}
+
+// nestedSegmentationUPIDs is the JSON representation of
+// SegmentationDescriptor.SegmentationUPIDs, in which multiple UPIDs are nested
+// within a single MID() SegmentationUPID. XML follows the SCTE 35 schema, in
+// which a MID() is a flat sequence of SegmentationUpid elements.
+type nestedSegmentationUPIDs []SegmentationUPID
+
+// midSegmentationUPID is a MID() SegmentationUPID with nested UPIDs. Format and
+// Value are only set for a MID() provided as base-64.
+type midSegmentationUPID struct {
+	Type   uint32             `json:"segmentationUpidType"`
+	Format string             `json:"segmentationUpidFormat,omitempty"`
+	Value  string             `json:"value,omitempty"`
+	UPIDs  []SegmentationUPID `json:"segmentationUpids,omitempty"`
+}
+
+// segmentationUPIDs returns the SegmentationUPIDs represented by this
+// midSegmentationUPID.
+func (mid *midSegmentationUPID) segmentationUPIDs() []SegmentationUPID {
+	if len(mid.UPIDs) > 0 {
+		return mid.UPIDs
+	}
+	return []SegmentationUPID{{Type: mid.Type, Format: mid.Format, Value: mid.Value}}
+}
+
+// MarshalJSON encodes multiple SegmentationUPIDs as a MID() SegmentationUPID.
+func (upids nestedSegmentationUPIDs) MarshalJSON() ([]byte, error) {
+	if len(upids) > 1 {
+		return json.Marshal([]midSegmentationUPID{{Type: SegmentationUPIDTypeMID, UPIDs: upids}})
+	}
+	return json.Marshal([]SegmentationUPID(upids))
+}
+
+// UnmarshalJSON decodes SegmentationUPIDs, flattening any nested within a
+// MID() SegmentationUPID.
+func (upids *nestedSegmentationUPIDs) UnmarshalJSON(b []byte) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(b, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		var mid midSegmentationUPID
+		if err := json.Unmarshal(item, &mid); err != nil {
+			return err
+		}
+		if mid.Type == SegmentationUPIDTypeMID {
+			*upids = append(*upids, mid.segmentationUPIDs()...)
+			continue
+		}
+		var upid SegmentationUPID
+		if err := json.Unmarshal(item, &upid); err != nil {
+			return err
+		}
+		*upids = append(*upids, upid)
+	}
+	return nil
+}
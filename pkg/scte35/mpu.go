@@ -0,0 +1,230 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"sync"
+
+	"github.com/bamiaux/iobit"
+)
+
+// MPUDecoder decodes the private_data of an MPU() into an MPUPayload.
+type MPUDecoder func(privateData []byte) (MPUPayload, error)
+
+// MPUPayload is the decoded private_data of an MPU().
+type MPUPayload interface {
+	// Bytes returns the binary private_data.
+	Bytes() []byte
+	// String returns a textual representation of the private_data.
+	String() string
+}
+
+var (
+	mpuDecodersMu sync.RWMutex
+	mpuDecoders   = map[uint32]MPUDecoder{}
+)
+
+func init() {
+	RegisterMPUDecoder("DISC", DecodeMPUTextPayload)
+	RegisterMPUDecoder("ADFR", DecodeMPUADFRPayload)
+}
+
+// RegisterMPUDecoder registers the MPUDecoder for a 4 character
+// format_identifier (ie, "DISC"), replacing any previously registered
+// MPUDecoder. RegisterMPUDecoder panics if formatIdentifier is not 4
+// characters.
+func RegisterMPUDecoder(formatIdentifier string, d MPUDecoder) {
+	if len(formatIdentifier) != 4 {
+		panic(fmt.Sprintf("scte35: format_identifier %q is not 4 characters", formatIdentifier))
+	}
+	mpuDecodersMu.Lock()
+	defer mpuDecodersMu.Unlock()
+	mpuDecoders[binary.BigEndian.Uint32([]byte(formatIdentifier))] = d
+}
+
+// UnregisterMPUDecoder removes the MPUDecoder registered for a 4 character
+// format_identifier, if any.
+func UnregisterMPUDecoder(formatIdentifier string) {
+	if len(formatIdentifier) != 4 {
+		return
+	}
+	mpuDecodersMu.Lock()
+	defer mpuDecodersMu.Unlock()
+	delete(mpuDecoders, binary.BigEndian.Uint32([]byte(formatIdentifier)))
+}
+
+// DecodeMPU decodes a binary MPU() structure. The private_data is decoded by
+// the MPUDecoder registered for the format_identifier, or as an
+// MPURawPayload if none is registered.
+func DecodeMPU(b []byte) (*MPU, error) {
+	if len(b) < 4 {
+		return nil, fmt.Errorf("mpu: %w: expected at least 4 bytes, got %d", ErrInvalidUPID, len(b))
+	}
+
+	mpu := &MPU{FormatIdentifier: binary.BigEndian.Uint32(b)}
+	mpuDecodersMu.RLock()
+	d, ok := mpuDecoders[mpu.FormatIdentifier]
+	mpuDecodersMu.RUnlock()
+	if !ok {
+		mpu.PrivateData = MPURawPayload(b[4:])
+		return mpu, nil
+	}
+
+	p, err := d(b[4:])
+	if err != nil {
+		return nil, fmt.Errorf("mpu: %s: %w", mpu.formatIdentifierString(), err)
+	}
+	mpu.PrivateData = p
+	return mpu, nil
+}
+
+// MPU is a structured Managed Private UPID (segmentation_upid_type 0x0C).
+type MPU struct {
+	FormatIdentifier uint32
+	PrivateData      MPUPayload
+}
+
+// Bytes returns the binary representation of this MPU.
+func (mpu *MPU) Bytes() []byte {
+	b := binary.BigEndian.AppendUint32(nil, mpu.FormatIdentifier)
+	if mpu.PrivateData != nil {
+		b = append(b, mpu.PrivateData.Bytes()...)
+	}
+	return b
+}
+
+// SegmentationUPID returns this MPU as a SegmentationUPID.
+func (mpu *MPU) SegmentationUPID() SegmentationUPID {
+	fi := mpu.FormatIdentifier
+	return SegmentationUPID{
+		Type:             SegmentationUPIDTypeMPU,
+		Format:           SegmentationUPIDFormatBase64,
+		FormatIdentifier: &fi,
+		Value:            base64.StdEncoding.EncodeToString(mpu.Bytes()[4:]),
+	}
+}
+
+// String returns the textual representation of this MPU, formatted as
+// <format_identifier>:<private_data>.
+func (mpu *MPU) String() string {
+	if mpu.PrivateData == nil {
+		return mpu.formatIdentifierString() + ":"
+	}
+	return mpu.formatIdentifierString() + ":" + mpu.PrivateData.String()
+}
+
+// formatIdentifierString returns the format_identifier as a string.
+func (mpu *MPU) formatIdentifierString() string {
+	return string(binary.BigEndian.AppendUint32(nil, mpu.FormatIdentifier))
+}
+
+// MPU returns the Value of an MPU SegmentationUPID as an MPU.
+func (upid *SegmentationUPID) MPU() (*MPU, error) {
+	if upid.Type != SegmentationUPIDTypeMPU {
+		return nil, fmt.Errorf("mpu: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	b, err := upid.valueBytes()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeMPU(b)
+}
+
+// DecodeMPUTextPayload is an MPUDecoder for private_data containing text.
+func DecodeMPUTextPayload(b []byte) (MPUPayload, error) {
+	return MPUTextPayload(b), nil
+}
+
+// MPUTextPayload is private_data containing text.
+type MPUTextPayload string
+
+// Bytes returns the private_data.
+func (p MPUTextPayload) Bytes() []byte {
+	return []byte(p)
+}
+
+// String returns the private_data.
+func (p MPUTextPayload) String() string {
+	return string(p)
+}
+
+// mpuADFRPayloadLength is the length of ADFR private_data, in bytes.
+const mpuADFRPayloadLength = 11
+
+// DecodeMPUADFRPayload is an MPUDecoder for the private_data of an ADFR
+// (addressable TV) MPU().
+func DecodeMPUADFRPayload(b []byte) (MPUPayload, error) {
+	if len(b) != mpuADFRPayloadLength {
+		return nil, fmt.Errorf("%w: expected %d bytes, got %d", ErrInvalidUPID, mpuADFRPayloadLength, len(b))
+	}
+	r := iobit.NewReader(b)
+	p := &MPUADFRPayload{}
+	p.Version = r.Uint32(8)
+	p.ChannelIdentifier = r.Uint32(16)
+	p.Date = r.Uint32(32)
+	p.BreakCode = r.Uint32(16)
+	p.Duration = r.Uint32(16)
+	return p, nil
+}
+
+// MPUADFRPayload is the private_data of an ADFR (addressable TV) MPU().
+type MPUADFRPayload struct {
+	// Version is the version of the ADFR private_data.
+	Version uint32
+	// ChannelIdentifier identifies the channel carrying the break.
+	ChannelIdentifier uint32
+	// Date is the date of the break, as YYYYMMDD.
+	Date uint32
+	// BreakCode identifies the break within the day.
+	BreakCode uint32
+	// Duration is the duration of the break.
+	Duration uint32
+}
+
+// Bytes returns the private_data.
+func (p *MPUADFRPayload) Bytes() []byte {
+	buf := make([]byte, mpuADFRPayloadLength)
+	iow := iobit.NewWriter(buf)
+	iow.PutUint32(8, p.Version)
+	iow.PutUint32(16, p.ChannelIdentifier)
+	iow.PutUint32(32, p.Date)
+	iow.PutUint32(16, p.BreakCode)
+	iow.PutUint32(16, p.Duration)
+	_ = iow.Flush()
+	return buf
+}
+
+// String returns the fields of the private_data.
+func (p *MPUADFRPayload) String() string {
+	return fmt.Sprintf("version=%d,channel_identifier=%#04x,date=%d,break_code=%d,duration=%d", p.Version, p.ChannelIdentifier, p.Date, p.BreakCode, p.Duration)
+}
+
+// MPURawPayload is private_data with no registered MPUDecoder.
+type MPURawPayload []byte
+
+// Bytes returns the private_data.
+func (p MPURawPayload) Bytes() []byte {
+	return p
+}
+
+// String returns the private_data as hexadecimal.
+func (p MPURawPayload) String() string {
+	return fmt.Sprintf("0x%X", []byte(p))
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"encoding/xml"
 	"fmt"
+	"slices"
 
 	"github.com/bamiaux/iobit"
 )
@@ -41,6 +42,13 @@ type PrivateCommand struct {
 	PrivateBytes Bytes    `xml:",chardata" json:"privateBytes"`
 }
 
+// Clone returns a deep copy of this PrivateCommand.
+func (cmd *PrivateCommand) Clone() SpliceCommand {
+	c := *cmd
+	c.PrivateBytes = slices.Clone(cmd.PrivateBytes)
+	return &c
+}
+
 // IdentifierString returns the identifier as a string.
 func (cmd *PrivateCommand) IdentifierString() string {
 	b := make([]byte, 4)
@@ -95,7 +103,7 @@ func (cmd *PrivateCommand) length() int {
 // writeTo the given table.
 func (cmd *PrivateCommand) writeTo(t *table) {
 	t.row(0, "private_command() {", nil)
-	t.row(1, "identifier", fmt.Sprintf("%#08x, (%s)", cmd.Identifier, cmd.IdentifierString()))
-	t.row(1, "private_byte", fmt.Sprintf("%#0x", cmd.PrivateBytes))
+	t.field(1, "identifier", fmt.Sprintf("%#08x, (%s)", cmd.Identifier, cmd.IdentifierString()), 32)
+	t.field(1, "private_byte", fmt.Sprintf("%#0x", cmd.PrivateBytes), len(cmd.PrivateBytes)*8)
 	t.row(0, "}", nil)
 }
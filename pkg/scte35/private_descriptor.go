@@ -20,6 +20,7 @@ import (
 	"encoding/binary"
 	"encoding/xml"
 	"fmt"
+	"slices"
 
 	"github.com/bamiaux/iobit"
 )
@@ -33,6 +34,13 @@ type PrivateDescriptor struct {
 	PrivateBytes Bytes    `xml:",chardata" json:"privateBytes"`
 }
 
+// Clone returns a deep copy of this PrivateDescriptor.
+func (sd *PrivateDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	c.PrivateBytes = slices.Clone(sd.PrivateBytes)
+	return &c
+}
+
 // IdentifierString returns the identifier as a string
 func (sd *PrivateDescriptor) IdentifierString() string {
 	b := make([]byte, 4)
@@ -93,9 +101,10 @@ func (sd *PrivateDescriptor) length() int {
 // table returns the tabular description of this PrivateDescriptor.
 func (sd *PrivateDescriptor) writeTo(t *table) {
 	t.row(0, "private_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x, (%s)", sd.Identifier, sd.IdentifierString()))
-	t.row(1, "private_bytes", fmt.Sprintf("%#0x", sd.PrivateBytes))
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x, (%s)", sd.Identifier, sd.IdentifierString()), 32)
+	t.field(1, "private_bytes", fmt.Sprintf("%#0x", sd.PrivateBytes), len(sd.PrivateBytes)*8)
+	t.end(1)
 	t.row(0, "}", nil)
 }
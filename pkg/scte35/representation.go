@@ -0,0 +1,204 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strings"
+)
+
+// Signal representations supported by ReadSignal and WriteSignal.
+const (
+	// RepresentationAuto detects the representation (ReadSignal only).
+	RepresentationAuto = "auto"
+	// RepresentationBase64 is base-64 encoded binary.
+	RepresentationBase64 = "base64"
+	// RepresentationHex is hexadecimal encoded binary, optionally 0x
+	// prefixed.
+	RepresentationHex = "hex"
+	// RepresentationBinary is raw binary.
+	RepresentationBinary = "binary"
+	// RepresentationJSON is JSON.
+	RepresentationJSON = "json"
+	// RepresentationXML is SCTE 35 XML <SpliceInfoSection>.
+	RepresentationXML = "xml"
+	// RepresentationSignal is SCTE 35 XML <Signal><Binary>.
+	RepresentationSignal = "signal"
+	// RepresentationText is the tabular description returned by
+	// SpliceInfoSection.Table (WriteSignal only).
+	RepresentationText = "text"
+)
+
+// Representations returns the representations supported by WriteSignal. All
+// but RepresentationText are supported by ReadSignal.
+func Representations() []string {
+	return []string{
+		RepresentationBase64,
+		RepresentationHex,
+		RepresentationBinary,
+		RepresentationJSON,
+		RepresentationXML,
+		RepresentationSignal,
+		RepresentationText,
+	}
+}
+
+// signalXML is the SCTE 35 XML <Signal> element, which carries either a
+// base-64 encoded <Binary> or a <SpliceInfoSection>.
+type signalXML struct {
+	XMLName           xml.Name
+	Binary            string             `xml:"Binary"`
+	SpliceInfoSection *SpliceInfoSection `xml:"SpliceInfoSection"`
+}
+
+// DetectRepresentation returns the representation of b.
+func DetectRepresentation(b []byte) string {
+	if len(b) > 0 && b[0] == TableID {
+		return RepresentationBinary
+	}
+	s := strings.TrimSpace(string(b))
+	switch {
+	case strings.HasPrefix(s, "{"):
+		return RepresentationJSON
+	case strings.HasPrefix(s, "<"):
+		var start struct{ XMLName xml.Name }
+		if err := xml.Unmarshal([]byte(s), &start); err == nil && start.XMLName.Local == "Signal" {
+			return RepresentationSignal
+		}
+		return RepresentationXML
+	case trimHexPrefix(s) != s, strings.HasPrefix(strings.ToLower(s), "fc"):
+		// base-64 signals always start with '/' (table_id 0xFC)
+		return RepresentationHex
+	default:
+		return RepresentationBase64
+	}
+}
+
+// ReadSignal decodes a SpliceInfoSection from b in the given representation.
+// If from is RepresentationAuto (or empty) the representation is detected.
+func ReadSignal(b []byte, from string) (*SpliceInfoSection, error) {
+	if from == RepresentationAuto || from == "" {
+		from = DetectRepresentation(b)
+	}
+
+	sis := &SpliceInfoSection{}
+	s := strings.TrimSpace(string(b))
+	switch from {
+	case RepresentationJSON:
+		return sis, json.Unmarshal([]byte(s), sis)
+	case RepresentationXML:
+		return sis, xml.Unmarshal([]byte(s), sis)
+	case RepresentationSignal:
+		var sig signalXML
+		if err := xml.Unmarshal([]byte(s), &sig); err != nil {
+			return sis, err
+		}
+		if sig.SpliceInfoSection != nil {
+			return sig.SpliceInfoSection, nil
+		}
+	}
+
+	bin, err := SignalBytes(b, from)
+	if err != nil {
+		return sis, err
+	}
+	return sis, sis.Decode(bin)
+}
+
+// SignalBytes returns the binary splice_info_section in b, in the given
+// representation. Binary, base-64, hexadecimal and <Signal><Binary> signals are
+// returned as received, without being decoded, while JSON and XML signals are
+// encoded. If from is RepresentationAuto (or empty) the representation is
+// detected.
+func SignalBytes(b []byte, from string) ([]byte, error) {
+	if from == RepresentationAuto || from == "" {
+		from = DetectRepresentation(b)
+	}
+
+	s := strings.TrimSpace(string(b))
+	switch from {
+	case RepresentationBinary:
+		return b, nil
+	case RepresentationBase64:
+		return decodeBase64(s)
+	case RepresentationHex:
+		return decodeHex(s)
+	case RepresentationSignal:
+		var sig signalXML
+		if err := xml.Unmarshal([]byte(s), &sig); err != nil {
+			return nil, err
+		}
+		if sig.SpliceInfoSection != nil {
+			return sig.SpliceInfoSection.Encode()
+		}
+		if sig.Binary == "" {
+			return nil, fmt.Errorf("signal: missing Binary or SpliceInfoSection")
+		}
+		return decodeBase64(strings.TrimSpace(sig.Binary))
+	case RepresentationJSON, RepresentationXML:
+		sis, err := ReadSignal(b, from)
+		if err != nil {
+			return nil, err
+		}
+		return sis.Encode()
+	default:
+		return nil, fmt.Errorf("unsupported representation: %q", from)
+	}
+}
+
+// WriteSignal encodes a SpliceInfoSection in the requested representation.
+// Text representations are terminated with a newline.
+func WriteSignal(sis *SpliceInfoSection, to string) ([]byte, error) {
+	var out []byte
+	if to == RepresentationBinary || to == RepresentationBase64 || to == RepresentationHex || to == RepresentationSignal {
+		b, err := sis.Encode()
+		if err != nil {
+			return nil, err
+		}
+		out = b
+	}
+
+	switch to {
+	case RepresentationBinary:
+		return out, nil
+	case RepresentationBase64:
+		return []byte(base64.StdEncoding.EncodeToString(out) + "\n"), nil
+	case RepresentationHex:
+		return []byte(hex.EncodeToString(out) + "\n"), nil
+	case RepresentationJSON:
+		b, err := json.MarshalIndent(sis, "", "\t")
+		return append(b, '\n'), err
+	case RepresentationXML:
+		b, err := xml.MarshalIndent(sis, "", "\t")
+		return append(b, '\n'), err
+	case RepresentationSignal:
+		sig := struct {
+			XMLName xml.Name `xml:"http://www.scte.org/schemas/35 Signal"`
+			Binary  string   `xml:"Binary"`
+		}{Binary: base64.StdEncoding.EncodeToString(out)}
+		b, err := xml.MarshalIndent(&sig, "", "\t")
+		return append(b, '\n'), err
+	case RepresentationText:
+		return []byte(sis.Table("", "\t") + "\n"), nil
+	default:
+		return nil, fmt.Errorf("unsupported representation: %q", to)
+	}
+}
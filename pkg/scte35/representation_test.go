@@ -0,0 +1,79 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35_test
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadSignal(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+
+	sis, err := scte35.DecodeBase64(signal)
+	require.NoError(t, err)
+	bin, err := base64.StdEncoding.DecodeString(signal)
+	require.NoError(t, err)
+	js, err := scte35.WriteSignal(sis, scte35.RepresentationJSON)
+	require.NoError(t, err)
+	x, err := scte35.WriteSignal(sis, scte35.RepresentationXML)
+	require.NoError(t, err)
+	sig, err := scte35.WriteSignal(sis, scte35.RepresentationSignal)
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		in       string
+		expected string
+	}{
+		"Binary":         {in: string(bin), expected: scte35.RepresentationBinary},
+		"Base64":         {in: signal, expected: scte35.RepresentationBase64},
+		"Hex":            {in: sis.Hex(), expected: scte35.RepresentationHex},
+		"Hex Upper Case": {in: strings.ToUpper(sis.Hex()), expected: scte35.RepresentationHex},
+		"Hex Prefix":     {in: "0x" + sis.Hex(), expected: scte35.RepresentationHex},
+		"Hex Upper Case Prefix": {
+			in:       "0X" + strings.ToUpper(sis.Hex()),
+			expected: scte35.RepresentationHex,
+		},
+		"Hex Whitespace": {in: " 0x" + sis.Hex() + "\n", expected: scte35.RepresentationHex},
+		"JSON":           {in: string(js), expected: scte35.RepresentationJSON},
+		"XML":            {in: string(x), expected: scte35.RepresentationXML},
+		"Signal":         {in: string(sig), expected: scte35.RepresentationSignal},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			require.Equal(t, c.expected, scte35.DetectRepresentation([]byte(c.in)))
+
+			decoded, err := scte35.ReadSignal([]byte(c.in), scte35.RepresentationAuto)
+			require.NoError(t, err)
+			require.True(t, scte35.Equal(sis, decoded))
+
+			decoded, err = scte35.ReadSignal([]byte(c.in), c.expected)
+			require.NoError(t, err)
+			require.True(t, scte35.Equal(sis, decoded))
+
+			b, err := scte35.SignalBytes([]byte(c.in), scte35.RepresentationAuto)
+			require.NoError(t, err)
+			require.Equal(t, bin, b)
+		})
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+)
+
+const (
+	// SCRTypeProgramIntermission is the SCR type for Program Intermission.
+	SCRTypeProgramIntermission = "PI"
+	// SCRTypeProgramRecap is the SCR type for Program Recap.
+	SCRTypeProgramRecap = "PR"
+	// SCRTypeDecisionPoint is the SCR type for a Decision Point for branching
+	// narratives.
+	SCRTypeDecisionPoint = "DP"
+	// SCRTypeClosingCredits is the SCR type for Closing Credits.
+	SCRTypeClosingCredits = "CC"
+	// SCRTypeOpeningCredits is the SCR type for Opening Credits.
+	SCRTypeOpeningCredits = "OC"
+	// SCRTypeOverlayedOpeningCredits is the SCR type for Overlayed Opening
+	// Credits.
+	SCRTypeOverlayedOpeningCredits = "OOC"
+)
+
+// ParseSCR parses an SCR from its key-value pair syntax (ie, type=PI&tier=1),
+// as defined in section 10.3.3.6. Keys other than type and tier are
+// permitted.
+func ParseSCR(s string) (*SCR, error) {
+	kvs, err := parseUPIDKeyValues(s)
+	if err != nil {
+		return nil, fmt.Errorf("scr: %w", err)
+	}
+	scr := &SCR{UPIDKeyValues: kvs}
+	for _, t := range scr.Get("type") {
+		switch t {
+		case SCRTypeProgramIntermission,
+			SCRTypeProgramRecap,
+			SCRTypeDecisionPoint,
+			SCRTypeClosingCredits,
+			SCRTypeOpeningCredits,
+			SCRTypeOverlayedOpeningCredits:
+		default:
+			return nil, fmt.Errorf("scr: %w: unknown type %q", ErrInvalidUPID, t)
+		}
+	}
+	if err := kvs.validateUint("tier"); err != nil {
+		return nil, fmt.Errorf("scr: %w", err)
+	}
+	return scr, nil
+}
+
+// SCR is a structured Segment Content Reference (segmentation_upid_type
+// 0x11).
+type SCR struct {
+	UPIDKeyValues
+}
+
+// Type returns the types of content referenced in the Segment (ie, PI).
+func (scr *SCR) Type() []string {
+	return scr.Get("type")
+}
+
+// Tier returns the authorization designation.
+func (scr *SCR) Tier() (uint64, bool) {
+	return scr.uint("tier")
+}
+
+// SegmentationUPID returns this SCR as a SegmentationUPID.
+func (scr *SCR) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeSCR,
+		Format: SegmentationUPIDFormatText,
+		Value:  scr.String(),
+	}
+}
+
+// SCR returns the Value of an SCR SegmentationUPID as an SCR.
+func (upid *SegmentationUPID) SCR() (*SCR, error) {
+	if upid.Type != SegmentationUPIDTypeSCR {
+		return nil, fmt.Errorf("scr: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseSCR(upid.Value)
+}
@@ -24,7 +24,6 @@ import (
 	"errors"
 	"log"
 	"math"
-	"strings"
 	"time"
 
 	"github.com/bamiaux/iobit"
@@ -38,6 +37,8 @@ const (
 	// unixEpochToGPSEpoch is the number of seconds between 1970-01-01T00:00:00Z
 	// (Unix Epoch) and 1980-01-06T00:00:00Z (GPS Epoch).
 	unixEpochToGPSEpoch = uint32(315964800)
+	// maxPTS is the maximum value of a 33-bit PTS.
+	maxPTS = uint64(1<<33 - 1)
 )
 
 var (
@@ -61,28 +62,55 @@ var Logger = log.Default()
 // was encountered.
 func DecodeBase64(s string) (*SpliceInfoSection, error) {
 	sis := &SpliceInfoSection{}
-	b, err := base64.StdEncoding.DecodeString(s)
+	b, err := decodeBase64(s)
 	if err != nil {
-		return sis, ErrUnsupportedEncoding
+		return sis, err
 	}
 	err = sis.Decode(b)
 	return sis, err
 }
 
-// DecodeHex is a convenience function for decoding a hexadecimal string into
-// a SpliceInfoSection. If an error occurs, the returned SpliceInfoSection
-// will contains the results of decoding up until the error condition
-// was encountered.
+// DecodeHex is a convenience function for decoding a hexadecimal string, with
+// an optional 0x or 0X prefix, into a SpliceInfoSection. If an error occurs,
+// the returned SpliceInfoSection will contains the results of decoding up
+// until the error condition was encountered.
 func DecodeHex(s string) (*SpliceInfoSection, error) {
 	sis := &SpliceInfoSection{}
-	b, err := hex.DecodeString(strings.TrimPrefix(s, "0x"))
+	b, err := decodeHex(s)
 	if err != nil {
-		return sis, ErrUnsupportedEncoding
+		return sis, err
 	}
 	err = sis.Decode(b)
 	return sis, err
 }
 
+// decodeBase64 returns the bytes of a base-64 encoded signal.
+func decodeBase64(s string) ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrUnsupportedEncoding
+	}
+	return b, nil
+}
+
+// decodeHex returns the bytes of a hexadecimal signal, with an optional 0x or
+// 0X prefix.
+func decodeHex(s string) ([]byte, error) {
+	b, err := hex.DecodeString(trimHexPrefix(s))
+	if err != nil {
+		return nil, ErrUnsupportedEncoding
+	}
+	return b, nil
+}
+
+// trimHexPrefix returns s without a leading 0x or 0X.
+func trimHexPrefix(s string) string {
+	if len(s) >= 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		return s[2:]
+	}
+	return s
+}
+
 // DurationToTicks converts a duration to 90MhZ ticks.
 func DurationToTicks(d time.Duration) uint64 {
 	return uint64(math.Ceil(float64(d) * TicksPerSecond / float64(time.Second)))
@@ -94,6 +122,12 @@ func TicksToDuration(ticks uint64) time.Duration {
 	return time.Duration(int64(s * float64(time.Second)))
 }
 
+// AddPTS adds delta 90KHz ticks to a 33-bit PTS, wrapping around at 2^33.
+func AddPTS(pts uint64, delta int64) uint64 {
+	const m = int64(maxPTS + 1)
+	return uint64((int64(pts&maxPTS) + delta%m + m) % m)
+}
+
 // BreakDuration specifies the duration of the commercial break(s). It may be
 // used to give the splicer an indication of when the break will be over and
 // when the network In Point will occur.
@@ -102,6 +136,16 @@ type BreakDuration struct {
 	Duration   uint64 `xml:"duration,attr" json:"duration"`
 }
 
+// clonePtr returns a pointer to a copy of the value referenced by p, or nil if
+// p is nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	c := *p
+	return &c
+}
+
 // Bytes is a byte array.
 type Bytes []byte
 
@@ -508,6 +508,30 @@ func TestDecodeBase64(t *testing.T) {
 				SAPType: 3,
 			},
 		},
+		"UMID UPID": {
+			binary: "/DBHAAAAAAAA///wBQb+cr0AUAAxAi9DVUVJAAAAAX+/BCAGCis0AQEBBQEBDSATAAAA0skDbI8ZU0OrcBTS1xi/2hABATpqSEs=",
+			expected: scte35.SpliceInfoSection{
+				EncryptedPacket: scte35.EncryptedPacket{CWIndex: 255},
+				SpliceCommand:   scte35.NewTimeSignal(0x072bd0050),
+				SpliceDescriptors: []scte35.SpliceDescriptor{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeProgramStart,
+						SegmentationUPIDs: []scte35.SegmentationUPID{
+							{
+								Type:   scte35.SegmentationUPIDTypeUMID,
+								Format: scte35.SegmentationUPIDFormatText,
+								Value:  "060A2B34.01010105.01010D20.13000000.D2C9036C.8F195343.AB7014D2.D718BFDA",
+							},
+						},
+						SegmentNum:       1,
+						SegmentsExpected: 1,
+					},
+				},
+				Tier:    4095,
+				SAPType: 3,
+			},
+		},
 		"Splice Null - Heartbeat": {
 			binary: "/DARAAAAAAAAAP/wAAAAAHpPv/8=",
 			expected: scte35.SpliceInfoSection{
@@ -669,6 +693,7 @@ func TestDecodeBase64(t *testing.T) {
 			if err != nil {
 				return
 			}
+			require.Equal(t, c.legacy, sis.LegacySpliceCommandLength())
 
 			// test encode/decode XML
 			encodedXML := toXML(sis)
@@ -820,6 +845,28 @@ func TestTicksToDuration(t *testing.T) {
 	}
 }
 
+func TestAddPTS(t *testing.T) {
+	cases := map[string]struct {
+		pts      uint64
+		delta    int64
+		expected uint64
+	}{
+		"Positive":           {pts: 100, delta: 50, expected: 150},
+		"Negative":           {pts: 100, delta: -50, expected: 50},
+		"Wraparound":         {pts: 0x1FFFFFFFF, delta: 2, expected: 1},
+		"Negative Wrap":      {pts: 1, delta: -2, expected: 0x1FFFFFFFF},
+		"Large Delta":        {pts: 1, delta: 3 << 33, expected: 1},
+		"Large Negative":     {pts: 1, delta: -(3 << 33) - 1, expected: 0},
+		"Out Of Range Input": {pts: 1<<33 + 5, delta: 0, expected: 5},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			require.Equal(t, c.expected, scte35.AddPTS(c.pts, c.delta))
+		})
+	}
+}
+
 // helper func to make test life a bit easier
 
 func toBytes(i uint64) []byte {
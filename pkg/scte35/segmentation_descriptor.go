@@ -17,8 +17,10 @@
 package scte35
 
 import (
+	"encoding/json"
 	"encoding/xml"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/bamiaux/iobit"
@@ -192,6 +194,18 @@ type SegmentationDescriptor struct {
 	SubSegmentsExpected                    *uint32                           `xml:"subSegmentsExpected,attr" json:"subSegmentsExpected,omitempty"`
 }
 
+// Clone returns a deep copy of this SegmentationDescriptor.
+func (sd *SegmentationDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	c.DeliveryRestrictions = clonePtr(sd.DeliveryRestrictions)
+	c.SegmentationUPIDs = cloneSegmentationUPIDs(sd.SegmentationUPIDs)
+	c.Components = slices.Clone(sd.Components)
+	c.SegmentationDuration = clonePtr(sd.SegmentationDuration)
+	c.SubSegmentNum = clonePtr(sd.SubSegmentNum)
+	c.SubSegmentsExpected = clonePtr(sd.SubSegmentsExpected)
+	return &c
+}
+
 // Name returns the human-readable string for the segmentation_type_id.
 func (sd *SegmentationDescriptor) Name() string {
 	switch sd.SegmentationTypeID {
@@ -316,18 +330,76 @@ func (sd *SegmentationDescriptor) SegmentationDurationFlag() bool {
 
 // SegmentationUpidLength return the segmentation_upid_length
 func (sd *SegmentationDescriptor) SegmentationUpidLength() int {
-	length := 0
-	if len(sd.SegmentationUPIDs) == 1 {
-		length += len(sd.SegmentationUPIDs[0].valueBytes()) * 8 // segmentation_upid() (bytes -> bits)
-	} else if len(sd.SegmentationUPIDs) > 1 {
-		// for MID, include type & length with each contained upid
-		for _, upid := range sd.SegmentationUPIDs {
-			length += 8                          // segmentation_upid_type
-			length += 8                          // segmentation_upid_length
-			length += len(upid.valueBytes()) * 8 // segmentation_upid (bytes -> bits)
+	_, vb, _ := sd.segmentationUPID()
+	return len(vb)
+}
+
+// SegmentationUpidType returns the segmentation_upid_type. MID() is returned
+// when more than one SegmentationUPID is present.
+func (sd *SegmentationDescriptor) SegmentationUpidType() uint32 {
+	upidType, _, _ := sd.segmentationUPID()
+	return upidType
+}
+
+// MID returns the SegmentationUPIDs as a MID. The UPIDs contained in any MID()
+// SegmentationUPID are flattened.
+func (sd *SegmentationDescriptor) MID() MID {
+	var mid MID
+	for _, upid := range sd.SegmentationUPIDs {
+		if upid.Type == SegmentationUPIDTypeMID {
+			if b, err := upid.base64Bytes(); err == nil {
+				nested, _ := DecodeMID(b)
+				mid.Add(nested...)
+				continue
+			}
 		}
+		mid.Add(upid)
+	}
+	return mid
+}
+
+// MarshalJSON encodes a SegmentationDescriptor to JSON. Multiple
+// SegmentationUPIDs are nested within a single MID() SegmentationUPID.
+func (sd *SegmentationDescriptor) MarshalJSON() ([]byte, error) {
+	type alias SegmentationDescriptor
+	tmp := struct {
+		JSONType             uint32                  `json:"type"`
+		DeliveryRestrictions *DeliveryRestrictions   `json:"deliveryRestrictions,omitempty"`
+		SegmentationUPIDs    nestedSegmentationUPIDs `json:"segmentationUpids,omitempty"`
+		*alias
+	}{SegmentationDescriptorTag, sd.DeliveryRestrictions, nestedSegmentationUPIDs(sd.MID()), (*alias)(sd)}
+	return json.Marshal(&tmp)
+}
+
+// UnmarshalJSON decodes a SegmentationDescriptor from JSON. Both nested and
+// flattened MID() representations are supported.
+func (sd *SegmentationDescriptor) UnmarshalJSON(b []byte) error {
+	type alias SegmentationDescriptor
+	var tmp struct {
+		alias
+		SegmentationUPIDs nestedSegmentationUPIDs `json:"segmentationUpids"`
+	}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
+	}
+	*sd = SegmentationDescriptor(tmp.alias)
+	sd.SegmentationUPIDs = tmp.SegmentationUPIDs
+	sd.SegmentationUPIDs = sd.MID()
+	return nil
+}
+
+// segmentationUPID returns the segmentation_upid_type and segmentation_upid().
+func (sd *SegmentationDescriptor) segmentationUPID() (uint32, []byte, error) {
+	switch len(sd.SegmentationUPIDs) {
+	case 0:
+		return SegmentationUPIDTypeNotUsed, nil, nil
+	case 1:
+		vb, err := sd.SegmentationUPIDs[0].valueBytes()
+		return sd.SegmentationUPIDs[0].Type, vb, err
+	default:
+		b, err := sd.MID().Bytes()
+		return SegmentationUPIDTypeMID, b, err
 	}
-	return length / 8
 }
 
 // decode updates this splice_descriptor from binary.
@@ -379,20 +451,11 @@ func (sd *SegmentationDescriptor) decode(b []byte) error {
 			segmentationUpidValue := r.Bytes(segmentationUpidLength)
 
 			if segmentationUpidType == SegmentationUPIDTypeMID {
-				upidr := iobit.NewReader(segmentationUpidValue)
-				sd.SegmentationUPIDs = []SegmentationUPID{}
-				for upidr.LeftBits() > 0 {
-					upidType := upidr.Uint32(8)
-					upidLength := int(upidr.Uint32(8))
-					upidValue := upidr.Bytes(upidLength)
-					if len(upidValue) < upidLength {
-						Logger.Printf("Cannot read value for segmentation_upid_type %d; %d of %d bytes remaining.", upidType, len(upidValue), upidLength)
-					}
-					sd.SegmentationUPIDs = append(
-						sd.SegmentationUPIDs,
-						NewSegmentationUPID(upidType, upidValue),
-					)
+				mid, err := DecodeMID(segmentationUpidValue)
+				if err != nil {
+					Logger.Printf("Malformed segmentation_upid: %s", err)
 				}
+				sd.SegmentationUPIDs = mid
 			} else {
 				sd.SegmentationUPIDs = []SegmentationUPID{
 					NewSegmentationUPID(segmentationUpidType, segmentationUpidValue),
@@ -426,6 +489,16 @@ func (sd *SegmentationDescriptor) decode(b []byte) error {
 	if err := readerError(r); err != nil {
 		return fmt.Errorf("segmentation_descriptor: %w", err)
 	}
+
+	// malformed Ad-IDs and ISCIs are decoded, but reported to the caller
+	for _, upid := range sd.SegmentationUPIDs {
+		switch upid.Type {
+		case SegmentationUPIDTypeAdID, SegmentationUPIDTypeISCI:
+			if err := upid.Validate(); err != nil {
+				return fmt.Errorf("segmentation_descriptor: %w", err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -471,25 +544,13 @@ func (sd *SegmentationDescriptor) encode() ([]byte, error) {
 			iow.PutUint64(40, *sd.SegmentationDuration)
 		}
 
-		switch len(sd.SegmentationUPIDs) {
-		case 0:
-			iow.PutUint32(8, 0x00) // segmentation_upid_type
-			iow.PutUint32(8, 0x00) // segmentation_upid_length
-		case 1:
-			vb := sd.SegmentationUPIDs[0].valueBytes()
-			iow.PutUint32(8, sd.SegmentationUPIDs[0].Type)
-			iow.PutUint32(8, uint32(len(vb)))
-			_, _ = iow.Write(vb)
-		default:
-			iow.PutUint32(8, SegmentationUPIDTypeMID)
-			iow.PutUint32(8, uint32(sd.SegmentationUpidLength()))
-			for _, upid := range sd.SegmentationUPIDs {
-				vb := upid.valueBytes()
-				iow.PutUint32(8, upid.Type)
-				iow.PutUint32(8, uint32(len(vb)))
-				_, _ = iow.Write(vb)
-			}
+		upidType, vb, err := sd.segmentationUPID()
+		if err != nil {
+			return buf, fmt.Errorf("segmentation_descriptor: %w", err)
 		}
+		iow.PutUint32(8, upidType)        // segmentation_upid_type
+		iow.PutUint32(8, uint32(len(vb))) // segmentation_upid_length
+		_, _ = iow.Write(vb)
 
 		iow.PutUint32(8, sd.SegmentationTypeID)
 		iow.PutUint32(8, sd.SegmentNum)
@@ -557,62 +618,113 @@ func (sd *SegmentationDescriptor) length() int {
 // table returns the tabular description of this SegmentationDescriptor.
 func (sd *SegmentationDescriptor) writeTo(t *table) {
 	t.row(0, "segmentation_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x (%s)", CUEIdentifier, CUEIASCII))
-	t.row(1, "segmentation_event_id", sd.SegmentationEventID)
-	t.row(1, "segmentation_event_cancel_indicator", sd.SegmentationEventCancelIndicator)
-	t.row(1, "segmentation_event_id_compliance_indicator", sd.SegmentationEventIDComplianceIndicator)
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", sd.Tag()), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x (%s)", CUEIdentifier, CUEIASCII), 32)
+	t.field(1, "segmentation_event_id", sd.SegmentationEventID, 32)
+	t.field(1, "segmentation_event_cancel_indicator", sd.SegmentationEventCancelIndicator, 1)
+	t.field(1, "segmentation_event_id_compliance_indicator", sd.SegmentationEventIDComplianceIndicator, 1)
+	t.reserved(1, 6)
 	if !sd.SegmentationEventCancelIndicator {
-		t.row(1, "program_segmentation_flag", sd.ProgramSegmentationFlag())
-		t.row(1, "segmentation_duration_flag", sd.SegmentationDurationFlag())
-		t.row(1, "delivery_not_restricted_flag", sd.DeliveryNotRestrictedFlag())
+		t.field(1, "program_segmentation_flag", sd.ProgramSegmentationFlag(), 1)
+		t.field(1, "segmentation_duration_flag", sd.SegmentationDurationFlag(), 1)
+		t.field(1, "delivery_not_restricted_flag", sd.DeliveryNotRestrictedFlag(), 1)
 		if sd.DeliveryRestrictions != nil {
-			t.row(1, "web_delivery_allowed_flag", sd.DeliveryRestrictions.WebDeliveryAllowedFlag)
-			t.row(1, "no_regional_blackout_flag", sd.DeliveryRestrictions.NoRegionalBlackoutFlag)
-			t.row(1, "archive_allowed_flag", sd.DeliveryRestrictions.ArchiveAllowedFlag)
-			t.row(1, "device_restrictions", fmt.Sprintf("%d (%s)", sd.DeliveryRestrictions.DeviceRestrictions, sd.DeliveryRestrictions.deviceRestrictionsName()))
+			t.field(1, "web_delivery_allowed_flag", sd.DeliveryRestrictions.WebDeliveryAllowedFlag, 1)
+			t.field(1, "no_regional_blackout_flag", sd.DeliveryRestrictions.NoRegionalBlackoutFlag, 1)
+			t.field(1, "archive_allowed_flag", sd.DeliveryRestrictions.ArchiveAllowedFlag, 1)
+			t.field(1, "device_restrictions", fmt.Sprintf("%d (%s)", sd.DeliveryRestrictions.DeviceRestrictions, sd.DeliveryRestrictions.deviceRestrictionsName()), 2)
+		} else {
+			t.reserved(1, 5)
 		}
 		if len(sd.Components) > 0 {
-			t.row(1, "component_count", len(sd.Components))
+			t.field(1, "component_count", len(sd.Components), 8)
 			for i, c := range sd.Components {
 				t.row(1, "component["+strconv.Itoa(i)+"] {", nil)
-				t.row(2, "component_tag", c.Tag)
-				t.row(2, "pts_offset", c.PTSOffset)
+				t.field(2, "component_tag", c.Tag, 8)
+				t.reserved(2, 7)
+				t.field(2, "pts_offset", c.PTSOffset, 33)
 				t.row(1, "}", nil)
 			}
 		}
 		if sd.SegmentationDurationFlag() {
-			t.row(1, "segmentation_duration", sd.SegmentationDuration)
+			t.field(1, "segmentation_duration", sd.SegmentationDuration, 40)
 		}
 
-		t.row(1, "segmentation_upid_length", sd.SegmentationUpidLength())
+		// segmentation_upid_type is shown with the UPID it describes, so the
+		// rows of a single UPID are not in binary order. A MID() is shown as
+		// its nested UPIDs.
+		upidStart := t.pos
+		upidType, _ := t.peek(8)
+		mid := len(sd.SegmentationUPIDs) != 1 || upidType == SegmentationUPIDTypeMID
+		if mid {
+			upid := SegmentationUPID{Type: uint32(upidType)}
+			t.hidden(1, "segmentation_upid_type", fmt.Sprintf("%#02x (%s)", upidType, upid.Name()), 8)
+		} else {
+			t.seek(upidStart + 8)
+		}
+		upidLength := t.length(1, "segmentation_upid_length", sd.SegmentationUpidLength(), 8)
+		t.begin("segmentation_upid_length", upidLength)
 		for i, u := range sd.SegmentationUPIDs {
 			t.row(1, "segmentation_upid["+strconv.Itoa(i)+"] {", nil)
-			t.row(2, "segmentation_upid_type", fmt.Sprintf("%#02x (%s)", u.Type, u.Name()))
+			if !mid {
+				t.seek(upidStart)
+			}
+			t.field(2, "segmentation_upid_type", fmt.Sprintf("%#02x (%s)", u.Type, u.Name()), 8)
+			valueLength := upidLength
+			if mid {
+				n, _ := t.peek(8)
+				valueLength = int(n)
+				t.hidden(2, "segmentation_upid_length", valueLength, 8)
+			} else {
+				t.seek(upidStart + 16)
+			}
 			if u.Type == SegmentationUPIDTypeMPU {
-				t.row(2, "format_identifier", u.formatIdentifierString())
+				t.field(2, "format_identifier", u.formatIdentifierString(), 32)
+				valueLength = max(valueLength-4, 0)
+			}
+			t.field(2, "segmentation_upid", u.Value, valueLength*8)
+			if u.Type == SegmentationUPIDTypeMPU {
+				if mpu, err := u.MPU(); err == nil {
+					t.row(2, "private_data", mpu.PrivateData.String())
+				}
+			}
+			if u.Type == SegmentationUPIDTypeATSC {
+				if id, err := u.ATSCContentIdentifier(); err == nil {
+					id.writeTo(t, 2)
+				}
 			}
-			t.row(2, "segmentation_upid", u.Value)
 			t.row(1, "}", nil)
 		}
+		t.end(1)
 	}
 
-	t.row(1, "segmentation_type_id", fmt.Sprintf("%#02x (%s)", sd.SegmentationTypeID, sd.Name()))
-	t.row(1, "segment_num", sd.SegmentNum)
-	t.row(1, "segments_expected", sd.SegmentsExpected)
+	// segmentation_type_id, segment_num and segments_expected are not
+	// encoded if the event is cancelled
+	bits := 8
+	if sd.SegmentationEventCancelIndicator {
+		bits = 0
+	}
+	t.field(1, "segmentation_type_id", fmt.Sprintf("%#02x (%s)", sd.SegmentationTypeID, sd.Name()), bits)
+	t.field(1, "segment_num", sd.SegmentNum, bits)
+	t.field(1, "segments_expected", sd.SegmentsExpected, bits)
+
+	// sub-segment fields are only shown for placement opportunity starts
+	field := t.hidden
 	switch sd.SegmentationTypeID {
 	case SegmentationTypeProviderPOStart,
 		SegmentationTypeDistributorPOStart,
 		SegmentationTypeProviderOverlayPOStart,
 		SegmentationTypeDistributorOverlayPOStart:
-		if sd.SubSegmentNum != nil {
-			t.row(1, "sub_segment_num", sd.SubSegmentNum)
-		}
-		if sd.SubSegmentsExpected != nil {
-			t.row(1, "sub_segments_expected", sd.SubSegmentsExpected)
-		}
+		field = t.field
+	}
+	if sd.SubSegmentNum != nil {
+		field(1, "sub_segment_num", sd.SubSegmentNum, 8)
+	}
+	if sd.SubSegmentsExpected != nil {
+		field(1, "sub_segments_expected", sd.SubSegmentsExpected, 8)
 	}
+	t.end(1)
 	t.row(0, "}", nil)
 }
 
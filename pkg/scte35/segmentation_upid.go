@@ -17,10 +17,12 @@
 package scte35
 
 import (
-	"bytes"
 	"encoding/base64"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -84,25 +86,45 @@ const (
 	SegmentationUPIDFormatPrivatePrefix = "private:"
 )
 
-// NewSegmentationUPID construct a new SegmentationUPID
+// ErrInvalidUPID is returned when a segmentation_upid value does not conform
+// to the format defined for its segmentation_upid_type.
+var ErrInvalidUPID = errors.New("invalid segmentation_upid")
+
+// NewSegmentationUPID construct a new SegmentationUPID. Values that do not
+// conform to the format defined for the given type are decoded as usual; use
+// SegmentationUPID.Validate to check them.
 func NewSegmentationUPID(upidType uint32, buf []byte) SegmentationUPID {
 	r := iobit.NewReader(buf)
 
 	switch upidType {
-	// EIDR - custom
+	// EIDR - canonical text
 	case SegmentationUPIDTypeEIDR:
 		return SegmentationUPID{
 			Type:   upidType,
 			Format: SegmentationUPIDFormatText,
 			Value:  canonicalEIDR(r.LeftBytes()),
 		}
-	// ISAN - base64
+	// ISAN - canonical text, base64 if not a valid ISAN
 	case SegmentationUPIDTypeISAN, SegmentationUPIDTypeISANDeprecated:
+		if isan, err := DecodeISAN(r.LeftBytes()); err == nil {
+			return SegmentationUPID{
+				Type:   upidType,
+				Format: SegmentationUPIDFormatText,
+				Value:  isan.String(),
+			}
+		}
 		return SegmentationUPID{
 			Type:   upidType,
 			Format: SegmentationUPIDFormatBase64,
 			Value:  base64.StdEncoding.EncodeToString(r.LeftBytes()),
 		}
+	// UMID - dotted hex
+	case SegmentationUPIDTypeUMID:
+		return SegmentationUPID{
+			Type:   upidType,
+			Format: SegmentationUPIDFormatText,
+			Value:  formatUMID(r.LeftBytes()),
+		}
 	// MPU - custom
 	case SegmentationUPIDTypeMPU:
 		fi := r.Uint32(32)
@@ -112,6 +134,37 @@ func NewSegmentationUPID(upidType uint32, buf []byte) SegmentationUPID {
 			FormatIdentifier: &fi,
 			Value:            base64.StdEncoding.EncodeToString(r.LeftBytes()),
 		}
+	// MID - base64; SegmentationDescriptor.MID returns the contained UPIDs
+	case SegmentationUPIDTypeMID:
+		return SegmentationUPID{
+			Type:   upidType,
+			Format: SegmentationUPIDFormatBase64,
+			Value:  base64.StdEncoding.EncodeToString(r.LeftBytes()),
+		}
+	// UUID - RFC 4122 text, base64 if not a valid UUID
+	case SegmentationUPIDTypeUUID:
+		if u, err := DecodeUUID(r.LeftBytes()); err == nil {
+			return u.SegmentationUPID()
+		}
+		return SegmentationUPID{
+			Type:   upidType,
+			Format: SegmentationUPIDFormatBase64,
+			Value:  base64.StdEncoding.EncodeToString(r.LeftBytes()),
+		}
+	// ATSC - structured binary
+	case SegmentationUPIDTypeATSC:
+		return SegmentationUPID{
+			Type:   upidType,
+			Format: SegmentationUPIDFormatBase64,
+			Value:  base64.StdEncoding.EncodeToString(r.LeftBytes()),
+		}
+	// URI - RFC 3986 text, percent-encoding any bytes that are not permitted
+	case SegmentationUPIDTypeURI:
+		return SegmentationUPID{
+			Type:   upidType,
+			Format: SegmentationUPIDFormatText,
+			Value:  encodeURI(r.LeftBytes()),
+		}
 	// TI - unsigned int
 	case SegmentationUPIDTypeTI:
 		return SegmentationUPID{
@@ -186,7 +239,7 @@ func (upid *SegmentationUPID) Name() string {
 // ASCIIValue returns Value as an ASCII string. Characters outside the printable
 // range are represented by a dot (".").
 func (upid *SegmentationUPID) ASCIIValue() string {
-	b := upid.valueBytes()
+	b, _ := upid.valueBytes()
 	rs := make([]byte, len(b))
 	for i := range b {
 		if b[i] > 31 && b[i] < 127 {
@@ -198,54 +251,164 @@ func (upid *SegmentationUPID) ASCIIValue() string {
 	return string(rs)
 }
 
-// compressEIRD returns a compressed EIDR.
-func (upid *SegmentationUPID) compressEIDR(s string) []byte {
-	parts := strings.FieldsFunc(s, func(r rune) bool {
-		return r == '.' || r == '/'
-	})
+// MarshalJSON encodes a SegmentationUPID to JSON. The fields of an ATSC
+// Content Identifier are included alongside the Value.
+func (upid *SegmentationUPID) MarshalJSON() ([]byte, error) {
+	type alias SegmentationUPID
+	tmp := struct {
+		*alias
+		atscContentIdentifierFields
+	}{(*alias)(upid), upid.atscContentIdentifierFields()}
+	return json.Marshal(&tmp)
+}
 
-	if len(parts) != 3 {
-		Logger.Printf("EIDR string contains too many parts: %s", s)
-		return []byte(s)
+// UnmarshalJSON decodes a SegmentationUPID from JSON. An ATSC Content
+// Identifier may be provided as its fields rather than a Value.
+func (upid *SegmentationUPID) UnmarshalJSON(b []byte) error {
+	type alias SegmentationUPID
+	tmp := struct {
+		*alias
+		atscContentIdentifierFields
+	}{alias: (*alias)(upid)}
+	if err := json.Unmarshal(b, &tmp); err != nil {
+		return err
 	}
+	return upid.setATSCContentIdentifierFields(&tmp.atscContentIdentifierFields)
+}
 
-	i, err := strconv.Atoi(parts[1])
-	if err != nil {
-		Logger.Printf("Non-canonical EIDR prefix: '%s'", s)
-		return []byte(s)
+// MarshalXML encodes a SegmentationUPID to XML. The fields of an ATSC Content
+// Identifier are included as attributes.
+func (upid *SegmentationUPID) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type alias SegmentationUPID
+	tmp := struct {
+		*alias
+		atscContentIdentifierFields
+	}{(*alias)(upid), upid.atscContentIdentifierFields()}
+	return e.EncodeElement(&tmp, start)
+}
+
+// UnmarshalXML decodes a SegmentationUPID from XML. An ATSC Content Identifier
+// may be provided as its fields rather than a Value.
+func (upid *SegmentationUPID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias SegmentationUPID
+	tmp := struct {
+		*alias
+		atscContentIdentifierFields
+	}{alias: (*alias)(upid)}
+	if err := d.DecodeElement(&tmp, &start); err != nil {
+		return err
 	}
+	return upid.setATSCContentIdentifierFields(&tmp.atscContentIdentifierFields)
+}
 
-	b := make([]byte, 12)
-	iow := iobit.NewWriter(b)
-	iow.PutUint32(16, uint32(i))
+// Validate returns an error if Value does not conform to the format defined
+// for the segmentation_upid_type. Types without a well-defined format are
+// always considered valid.
+func (upid *SegmentationUPID) Validate() error {
+	var err error
+	switch upid.Type {
+	case SegmentationUPIDTypeAdID:
+		_, err = ParseAdID(upid.Value)
+	case SegmentationUPIDTypeISCI:
+		_, err = ParseISCI(upid.Value)
+	case SegmentationUPIDTypeUMID:
+		_, err = ParseUMID(upid.Value)
+	case SegmentationUPIDTypeISAN, SegmentationUPIDTypeISANDeprecated:
+		_, err = upid.ISAN()
+	case SegmentationUPIDTypeEIDR:
+		_, err = upid.EIDR()
+	case SegmentationUPIDTypeATSC:
+		_, err = upid.ATSCContentIdentifier()
+	case SegmentationUPIDTypeADI:
+		_, err = upid.ADI()
+	case SegmentationUPIDTypeADS:
+		_, err = upid.ADS()
+	case SegmentationUPIDTypeSCR:
+		_, err = upid.SCR()
+	case SegmentationUPIDTypeUUID:
+		_, err = upid.UUID()
+	case SegmentationUPIDTypeURI:
+		_, err = upid.URI()
+	case SegmentationUPIDTypeMPU:
+		_, err = upid.MPU()
+	case SegmentationUPIDTypeMID:
+		err = upid.validateMID()
+	}
+	return err
+}
 
-	h, err := hex.DecodeString(strings.ReplaceAll(parts[2], "-", ""))
+// validateMID returns an error if a MID() cannot be decoded or any of the
+// SegmentationUPIDs it contains are invalid.
+func (upid *SegmentationUPID) validateMID() error {
+	b, err := upid.base64Bytes()
+	if err != nil {
+		return fmt.Errorf("mid: %w", err)
+	}
+	mid, err := DecodeMID(b)
 	if err != nil {
-		Logger.Printf("Non-canonical EIDR suffix: '%s'", s)
-		return []byte(s)
+		return err
+	}
+	for i := range mid {
+		if err := mid[i].Validate(); err != nil {
+			return fmt.Errorf("mid: %w", err)
+		}
 	}
+	return nil
+}
 
-	_, _ = iow.Write(h)
-	_ = iow.Flush()
+// compressEIDR returns the compact binary representation of an EIDR. Values
+// that are not DOIs or have an unknown registrant prefix cannot be compacted and
+// return an error; use SegmentationUPIDFormatBase64 to encode them as-is.
+func compressEIDR(s string) ([]byte, error) {
+	e, err := ParseEIDR(s)
+	if err != nil {
+		return nil, err
+	}
+	return e.Bytes(), nil
+}
 
-	return b
+// clone returns a deep copy of this SegmentationUPID.
+func (upid *SegmentationUPID) clone() SegmentationUPID {
+	c := *upid
+	c.FormatIdentifier = clonePtr(upid.FormatIdentifier)
+	return c
 }
 
-// eidrTypeName returns the EIDR type name.
-func (upid *SegmentationUPID) eidrTypeName() string {
-	if strings.HasPrefix(upid.Value, "10.5237") {
-		return "Party ID"
+// atscContentIdentifierFields returns the marshalled fields of an ATSC Content
+// Identifier. No fields are set for any other segmentation_upid_type.
+func (upid *SegmentationUPID) atscContentIdentifierFields() atscContentIdentifierFields {
+	if upid.Type != SegmentationUPIDTypeATSC {
+		return atscContentIdentifierFields{}
 	}
-	if strings.HasPrefix(upid.Value, "10.5238") {
-		return "User ID"
+	b, err := upid.valueBytes()
+	if err != nil || len(b) < atscContentIdentifierHeaderLength {
+		return atscContentIdentifierFields{}
+	}
+	id, _ := DecodeATSCContentIdentifier(b)
+	return id.fields()
+}
+
+// setATSCContentIdentifierFields sets the Value of an ATSC Content Identifier
+// from its unmarshalled fields, if no Value was provided.
+func (upid *SegmentationUPID) setATSCContentIdentifierFields(f *atscContentIdentifierFields) error {
+	if upid.Type != SegmentationUPIDTypeATSC || strings.TrimSpace(upid.Value) != "" {
+		return nil
 	}
-	if strings.HasPrefix(upid.Value, "10.5239") {
-		return "Service ID"
+	id, err := f.atscContentIdentifier()
+	if err != nil || id == nil {
+		return err
 	}
-	if strings.HasPrefix(upid.Value, "10.5240") {
-		return "Content ID"
+	*upid = id.SegmentationUPID()
+	return nil
+}
+
+// eidrTypeName returns the EIDR type name.
+func (upid *SegmentationUPID) eidrTypeName() string {
+	e, err := upid.EIDR()
+	if err != nil {
+		return ""
 	}
-	return ""
+	return e.TypeName()
 }
 
 // formatIdentifierString returns the format identifier as a string
@@ -255,65 +418,128 @@ func (upid *SegmentationUPID) formatIdentifierString() string {
 	return string(b)
 }
 
-// valueBytes returns the value as a byte array.
-func (upid *SegmentationUPID) valueBytes() []byte {
+// valueBytes returns the value as a byte array. An error is returned if the
+// value cannot be represented in the binary form defined for its type.
+func (upid *SegmentationUPID) valueBytes() ([]byte, error) {
 	upid.Value = strings.TrimSpace(upid.Value)
 
 	// this switch should align with the constructor above
 	switch upid.Type {
-	// EIDR - custom
+	// EIDR - canonical text or base64
 	case SegmentationUPIDTypeEIDR:
-		return upid.compressEIDR(upid.Value)
-	// ISAN - base64
+		if upid.Format != SegmentationUPIDFormatBase64 {
+			return compressEIDR(upid.Value)
+		}
+		return upid.base64Bytes()
+	// ISAN - canonical text or base64
 	case SegmentationUPIDTypeISAN, SegmentationUPIDTypeISANDeprecated:
-		b, err := base64.StdEncoding.DecodeString(upid.Value)
-		if err != nil {
-			Logger.Printf("Error parsing UPID value: %s", err)
-			return b
+		if upid.Format != SegmentationUPIDFormatBase64 {
+			isan, err := ParseISAN(upid.Value)
+			if err != nil {
+				return nil, err
+			}
+			return isan.Bytes(), nil
+		}
+		return upid.base64Bytes()
+	// UMID - dotted hex, or text for values encoded prior to structured support
+	case SegmentationUPIDTypeUMID:
+		if b, err := hex.DecodeString(strings.ReplaceAll(upid.Value, ".", "")); err == nil {
+			return b, nil
 		}
-		return b
+		b, _ := charmap.ISO8859_1.NewEncoder().Bytes([]byte(upid.Value))
+		return b, nil
 	// MPU - custom
 	case SegmentationUPIDTypeMPU:
+		if upid.FormatIdentifier == nil {
+			return nil, fmt.Errorf("mpu: %w: format_identifier is missing", ErrInvalidUPID)
+		}
 		b := make([]byte, 4)
 		binary.BigEndian.PutUint32(b, *upid.FormatIdentifier)
-		v, err := base64.StdEncoding.DecodeString(upid.Value)
+		v, err := upid.base64Bytes()
 		if err != nil {
-			Logger.Printf("Error parsing UPID value: %s", err)
-			return b
+			return b, err
 		}
 		b = append(b, v...)
-		return b
+		return b, nil
+	// MID - base64
+	case SegmentationUPIDTypeMID:
+		return upid.base64Bytes()
+	// UUID - RFC 4122 text or base64, or raw text for values encoded prior to
+	// structured support
+	case SegmentationUPIDTypeUUID:
+		if upid.Format != SegmentationUPIDFormatBase64 {
+			u, err := ParseUUID(upid.Value)
+			if err != nil {
+				if b, lerr := charmap.ISO8859_1.NewEncoder().Bytes([]byte(upid.Value)); lerr == nil && len(b) == UUIDLength {
+					return b, nil
+				}
+				return nil, err
+			}
+			return u.Bytes(), nil
+		}
+		return upid.base64Bytes()
+	// ATSC - base64, or text for values encoded prior to structured support
+	case SegmentationUPIDTypeATSC:
+		if upid.Format == SegmentationUPIDFormatBase64 {
+			return upid.base64Bytes()
+		}
+		b, _ := charmap.ISO8859_1.NewEncoder().Bytes([]byte(upid.Value))
+		return b, nil
+	// URI - RFC 3986 text
+	case SegmentationUPIDTypeURI:
+		if _, err := ParseURI(upid.Value); err != nil {
+			return nil, err
+		}
+		return []byte(upid.Value), nil
 	// TI - unsigned int
 	case SegmentationUPIDTypeTI:
 		b := make([]byte, 8)
 		i, err := strconv.ParseUint(strings.TrimSpace(upid.Value), 10, 64)
 		if err != nil {
-			Logger.Printf("Error parsing UPID value: %s", err)
-			return b
+			return b, fmt.Errorf("ti: %w: %s", ErrInvalidUPID, err)
 		}
 		binary.BigEndian.PutUint64(b, i)
-		return b
+		return b, nil
 	// everything else - plain text
 	default:
 		// encode UTF8 values as Latin1 (reversing the Decode above)
 		b, _ := charmap.ISO8859_1.NewEncoder().Bytes([]byte(upid.Value))
-		return b
+		return b, nil
 	}
 }
 
-// canonicalEIDR returns a canonical EIDR.
-func canonicalEIDR(b []byte) string {
-	// already canonical
-	if bytes.Contains(b, []byte("/")) {
-		return string(b)
+// base64Bytes returns the value decoded as base-64.
+func (upid *SegmentationUPID) base64Bytes() ([]byte, error) {
+	b, err := base64.StdEncoding.DecodeString(upid.Value)
+	if err != nil {
+		return b, fmt.Errorf("%w: %s", ErrInvalidUPID, err)
 	}
+	return b, nil
+}
 
-	// dunno what this is
-	if len(b) != 12 {
-		Logger.Printf("Unexpected eidr value received: %s", b)
-		return ""
+// canonicalEIDR returns the textual representation of a binary EIDR. Compact
+// EIDRs with an unknown registrant prefix are still rendered as DOIs, and
+// values of any other length are returned as text.
+func canonicalEIDR(b []byte) string {
+	if len(b) == EIDRLength {
+		// a compact EIDR may also contain a "/" (0x2F), so decode it first
+		e, _ := DecodeEIDR(b)
+		return e.String()
 	}
 
-	i := int(binary.BigEndian.Uint16(b[:2]))
-	return fmt.Sprintf("10.%d/%X-%X-%X-%X-%X", i, b[2:4], b[4:6], b[6:8], b[8:10], b[10:12])
+	// already canonical, or dunno what this is
+	s, _ := charmap.ISO8859_1.NewDecoder().Bytes(b)
+	return string(s)
+}
+
+// cloneSegmentationUPIDs returns a deep copy of upids.
+func cloneSegmentationUPIDs(upids []SegmentationUPID) []SegmentationUPID {
+	if upids == nil {
+		return nil
+	}
+	c := make([]SegmentationUPID, len(upids))
+	for i := range upids {
+		c[i] = upids[i].clone()
+	}
+	return c
 }
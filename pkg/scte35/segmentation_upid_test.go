@@ -1,7 +1,15 @@
 package scte35_test
 
 import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/Comcast/scte35-go/pkg/scte35"
 	"github.com/stretchr/testify/require"
@@ -27,3 +35,972 @@ func TestSegmentationUPID_ASCIIValue(t *testing.T) {
 		})
 	}
 }
+
+func TestSegmentationUPID_AdID(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected scte35.AdID
+		err      error
+	}{
+		"Valid": {
+			value:    "ABCD01234567",
+			expected: scte35.AdID{Prefix: "ABCD", Code: "01234567"},
+		},
+		"Valid HD": {
+			value:    "ABCD0001000H",
+			expected: scte35.AdID{Prefix: "ABCD", Code: "0001000", Suffix: scte35.AdIDSuffixHD},
+		},
+		"Too Short": {
+			value: "ABCD0001000",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Numeric Prefix": {
+			value: "AB120001000H",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Invalid Code": {
+			value: "ABCD0001-00H",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeAdID, []byte(c.value))
+			require.ErrorIs(t, upid.Validate(), c.err)
+			id, err := upid.AdID()
+			require.ErrorIs(t, err, c.err)
+			require.Equal(t, c.expected, id)
+			if err == nil {
+				require.Equal(t, c.value, id.String())
+			}
+		})
+	}
+
+	// malformed values are reported by Validate rather than logged
+	var buf bytes.Buffer
+	scte35.Logger.SetOutput(&buf)
+	defer scte35.Logger.SetOutput(os.Stderr)
+	upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeAdID, []byte("AB120001000H"))
+	require.ErrorIs(t, upid.Validate(), scte35.ErrInvalidUPID)
+	require.Empty(t, buf.String())
+}
+
+func TestSegmentationUPID_DecodeMalformed(t *testing.T) {
+	cases := map[string]struct {
+		upid scte35.SegmentationUPID
+		err  error
+	}{
+		"Valid Ad-ID": {
+			upid: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD0001000H"},
+		},
+		"Malformed Ad-ID": {
+			upid: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "AB120001000H"},
+			err:  scte35.ErrInvalidUPID,
+		},
+		"Malformed ISCI": {
+			upid: scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeISCI, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD12E4"},
+			err:  scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis := scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationTypeID: scte35.SegmentationTypeProviderAdStart,
+						SegmentationUPIDs:  []scte35.SegmentationUPID{c.upid},
+					},
+					&scte35.DTMFDescriptor{Preroll: 10, DTMFChars: "123"},
+				},
+				Tier: 4095,
+			}
+			b, err := sis.Encode()
+			require.NoError(t, err)
+
+			// the section is fully decoded, and the malformed UPID is reported
+			decoded := &scte35.SpliceInfoSection{}
+			err = decoded.Decode(b)
+			require.ErrorIs(t, err, c.err)
+			require.Len(t, decoded.SpliceDescriptors, 2)
+			require.Equal(t, c.upid, decoded.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationUPIDs[0])
+			require.Equal(t, sis.SpliceDescriptors[1], decoded.SpliceDescriptors[1])
+			require.Equal(t, sis.Base64(), decoded.Base64())
+		})
+	}
+}
+
+func TestSegmentationUPID_ISCI(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected scte35.ISCI
+		err      error
+	}{
+		"Valid": {
+			value:    "ABCD1234",
+			expected: scte35.ISCI{Prefix: "ABCD", Code: "1234"},
+		},
+		"Too Long": {
+			value: "ABCD12345",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Alpha Code": {
+			value: "ABCD12E4",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeISCI, []byte(c.value))
+			id, err := upid.ISCI()
+			require.ErrorIs(t, err, c.err)
+			require.Equal(t, c.expected, id)
+		})
+	}
+
+	// type mismatch
+	upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeAdID, []byte("ABCD1234"))
+	_, err := upid.ISCI()
+	require.ErrorIs(t, err, scte35.ErrInvalidUPID)
+}
+
+func TestSegmentationUPID_UMID(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected *scte35.UMID
+		err      error
+	}{
+		"Basic": {
+			value: "060A2B34.01010105.01010D20.13000000.D2C9036C.8F195343.AB7014D2.D718BFDA",
+			expected: &scte35.UMID{
+				UniversalLabel: [12]byte{0x06, 0x0A, 0x2B, 0x34, 0x01, 0x01, 0x01, 0x05, 0x01, 0x01, 0x0D, 0x20},
+				Length:         0x13,
+				MaterialNumber: [16]byte{0xD2, 0xC9, 0x03, 0x6C, 0x8F, 0x19, 0x53, 0x43, 0xAB, 0x70, 0x14, 0xD2, 0xD7, 0x18, 0xBF, 0xDA},
+			},
+		},
+		"Extended": {
+			value: "060A2B34.01010105.01010D23.33000001.D2C9036C.8F195343.AB7014D2.D718BFDA.00000000.00000001.00000000.00000000.00000000.55534100.434D4353.41424344",
+			expected: &scte35.UMID{
+				UniversalLabel: [12]byte{0x06, 0x0A, 0x2B, 0x34, 0x01, 0x01, 0x01, 0x05, 0x01, 0x01, 0x0D, 0x23},
+				Length:         0x33,
+				InstanceNumber: 1,
+				MaterialNumber: [16]byte{0xD2, 0xC9, 0x03, 0x6C, 0x8F, 0x19, 0x53, 0x43, 0xAB, 0x70, 0x14, 0xD2, 0xD7, 0x18, 0xBF, 0xDA},
+				SourcePack: &scte35.UMIDSourcePack{
+					TimeDate:     [8]byte{0, 0, 0, 0, 0, 0, 0, 1},
+					Country:      [4]byte{'U', 'S', 'A', 0},
+					Organization: [4]byte{'C', 'M', 'C', 'S'},
+					User:         [4]byte{'A', 'B', 'C', 'D'},
+				},
+			},
+		},
+		"Invalid Length": {
+			value: "060A2B34.01010105.01010D20.13000000",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Invalid Label": {
+			value: "00000000.01010105.01010D20.13000000.D2C9036C.8F195343.AB7014D2.D718BFDA",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			b, err := hex.DecodeString(strings.ReplaceAll(c.value, ".", ""))
+			require.NoError(t, err)
+
+			// binary -> text
+			upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeUMID, b)
+			require.Equal(t, scte35.SegmentationUPIDFormatText, upid.Format)
+			require.Equal(t, c.value, upid.Value)
+
+			// text -> structured
+			umid, err := upid.UMID()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.expected, umid)
+			require.Equal(t, b, umid.Bytes())
+			require.Equal(t, upid, umid.SegmentationUPID())
+		})
+	}
+
+	// values encoded as text by earlier releases are still encoded as text
+	legacy := `{"segmentationUpidType":4,"segmentationUpidFormat":"text","value":"\u0006\n+4\u0001\u0001\u0001\u0005"}`
+	var upid scte35.SegmentationUPID
+	require.NoError(t, json.Unmarshal([]byte(legacy), &upid))
+	sis := scte35.SpliceInfoSection{
+		SpliceCommand: scte35.NewTimeSignal(0),
+		SpliceDescriptors: scte35.SpliceDescriptors{
+			&scte35.SegmentationDescriptor{
+				SegmentationTypeID: scte35.SegmentationTypeProgramStart,
+				SegmentationUPIDs:  []scte35.SegmentationUPID{upid},
+			},
+		},
+		Tier: 4095,
+	}
+	decoded, err := scte35.DecodeBase64(sis.Base64())
+	require.NoError(t, err)
+	require.Equal(t,
+		"060A2B34.01010105",
+		decoded.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationUPIDs[0].Value,
+	)
+}
+
+func TestSegmentationUPID_ISAN(t *testing.T) {
+	cases := map[string]struct {
+		upidType uint32
+		binary   string
+		text     string
+		expected *scte35.ISAN
+		err      error
+	}{
+		"Versioned": {
+			upidType: scte35.SegmentationUPIDTypeISAN,
+			binary:   "000000003A8D000000000000",
+			text:     "0000-0000-3A8D-0000-Z-0000-0000-6",
+			expected: &scte35.ISAN{Root: 0x3A8D, Version: ptr(uint32(0))},
+		},
+		"Versioned With Label": {
+			upidType: scte35.SegmentationUPIDTypeISAN,
+			binary:   "000000012C52000000000000",
+			text:     "ISAN 0000-0001-2C52-0000-P-0000-0000-0",
+			expected: &scte35.ISAN{Root: 0x12C52, Version: ptr(uint32(0))},
+		},
+		"Deprecated": {
+			upidType: scte35.SegmentationUPIDTypeISANDeprecated,
+			binary:   "000000003A8D0000",
+			text:     "0000-0000-3A8D-0000-Z",
+			expected: &scte35.ISAN{Root: 0x3A8D},
+		},
+		"Invalid Check Character": {
+			upidType: scte35.SegmentationUPIDTypeISAN,
+			text:     "0000-0000-3A8D-0000-Z-0000-0000-7",
+			err:      scte35.ErrInvalidUPID,
+		},
+		"Invalid Format": {
+			upidType: scte35.SegmentationUPIDTypeISAN,
+			text:     "0000-0000-3A8D-0000",
+			err:      scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: c.upidType, Format: scte35.SegmentationUPIDFormatText, Value: c.text}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			isan, err := upid.ISAN()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.expected, isan)
+
+			// binary -> canonical text
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+			require.Equal(t, b, isan.Bytes())
+			decoded := scte35.NewSegmentationUPID(c.upidType, b)
+			require.Equal(t, scte35.SegmentationUPIDFormatText, decoded.Format)
+			require.Equal(t, strings.TrimPrefix(c.text, "ISAN "), decoded.Value)
+			require.Equal(t, decoded, isan.SegmentationUPID())
+		})
+	}
+
+	// base-64 values are still supported
+	upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeISAN, Format: scte35.SegmentationUPIDFormatBase64, Value: "AAAAADqNAAAAAAAA"}
+	isan, err := upid.ISAN()
+	require.NoError(t, err)
+	require.Equal(t, "0000-0000-3A8D-0000-Z-0000-0000-6", isan.String())
+}
+
+func TestSegmentationUPID_EIDR(t *testing.T) {
+	cases := map[string]struct {
+		text     string
+		binary   string
+		value    string
+		typeName string
+		err      error
+	}{
+		"Content ID With Check Character": {
+			text:     "10.5240/0E4F-892E-442F-6BD4-15B0-1",
+			binary:   "14780E4F892E442F6BD415B0",
+			value:    "10.5240/0E4F-892E-442F-6BD4-15B0",
+			typeName: "Content ID",
+		},
+		"Service ID": {
+			text:     "10.5239/C370-DCA5",
+			binary:   "1477C370DCA5000000000000",
+			value:    "10.5239/C370-DCA5-0000-0000-0000",
+			typeName: "Service ID",
+		},
+		"Invalid Check Character": {
+			text: "10.5240/0E4F-892E-442F-6BD4-15B0-2",
+			err:  scte35.ErrInvalidUPID,
+		},
+		"Unknown Registrant Prefix": {
+			text: "10.1234/0E4F-892E-442F-6BD4-15B0",
+			err:  scte35.ErrInvalidUPID,
+		},
+		"Invalid Suffix": {
+			text: "10.5240/0E4F-892E-442F-6BD4-15B0-0000",
+			err:  scte35.ErrInvalidUPID,
+		},
+		"Invalid Format": {
+			text: "0E4F-892E-442F-6BD4-15B0",
+			err:  scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeEIDR, Format: scte35.SegmentationUPIDFormatText, Value: c.text}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			eidr, err := upid.EIDR()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.typeName, eidr.TypeName())
+
+			// binary -> text
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+			require.Equal(t, b, eidr.Bytes())
+			decoded := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeEIDR, b)
+			require.Equal(t, c.value, decoded.Value)
+			require.Equal(t, decoded, eidr.SegmentationUPID())
+		})
+	}
+
+	// unknown registrants and unexpected lengths are decoded as text and
+	// reported by Validate
+	for value, binary := range map[string]string{
+		"10.1234/0E4F-892E-442F-6BD4-15B0": "04D20E4F892E442F6BD415B0",
+		"0E4F892E":                         "3045344638393245",
+	} {
+		b, err := hex.DecodeString(binary)
+		require.NoError(t, err)
+		upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeEIDR, b)
+		require.Equal(t, scte35.SegmentationUPIDFormatText, upid.Format)
+		require.Equal(t, value, upid.Value)
+		require.ErrorIs(t, upid.Validate(), scte35.ErrInvalidUPID)
+
+	}
+
+	// invalid values are reported when encoding
+	for k, upid := range map[string]scte35.SegmentationUPID{
+		"Invalid Check Character":   {Type: scte35.SegmentationUPIDTypeEIDR, Value: "10.5240/0E4F-892E-442F-6BD4-15B0-2"},
+		"Unknown Registrant Prefix": {Type: scte35.SegmentationUPIDTypeEIDR, Value: "10.1234/0E4F-892E-442F-6BD4-15B0"},
+		"Not A DOI":                 {Type: scte35.SegmentationUPIDTypeEIDR, Value: "0E4F892E"},
+		"Invalid Base-64":           {Type: scte35.SegmentationUPIDTypeEIDR, Format: scte35.SegmentationUPIDFormatBase64, Value: "!"},
+	} {
+		t.Run(k, func(t *testing.T) {
+			sis := scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationTypeID: scte35.SegmentationTypeProgramStart,
+						SegmentationUPIDs:  []scte35.SegmentationUPID{upid},
+					},
+				},
+				Tier: 4095,
+			}
+			_, err := sis.Encode()
+			require.ErrorIs(t, err, scte35.ErrInvalidUPID)
+		})
+	}
+
+	// base-64 values are encoded as is
+	sis := scte35.SpliceInfoSection{
+		SpliceCommand: scte35.NewTimeSignal(0),
+		SpliceDescriptors: scte35.SpliceDescriptors{
+			&scte35.SegmentationDescriptor{
+				SegmentationTypeID: scte35.SegmentationTypeProgramStart,
+				SegmentationUPIDs: []scte35.SegmentationUPID{
+					{Type: scte35.SegmentationUPIDTypeEIDR, Format: scte35.SegmentationUPIDFormatBase64, Value: "BNIOT4kuRC9r1BWw"},
+				},
+			},
+		},
+		Tier: 4095,
+	}
+	decoded, err := scte35.DecodeBase64(sis.Base64())
+	require.NoError(t, err)
+	require.Equal(t,
+		"10.1234/0E4F-892E-442F-6BD4-15B0",
+		decoded.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationUPIDs[0].Value,
+	)
+}
+
+func TestSegmentationUPID_ATSCContentIdentifier(t *testing.T) {
+	cases := map[string]struct {
+		binary   string
+		expected *scte35.ATSCContentIdentifier
+		err      error
+	}{
+		"Valid": {
+			binary: "1234CA07414243",
+			expected: &scte35.ATSCContentIdentifier{
+				TSID:      0x1234,
+				Reserved:  0x03,
+				EndOfDay:  5,
+				UniqueFor: 7,
+				ContentID: []byte("ABC"),
+			},
+		},
+		"Empty Content ID": {
+			binary: "1234C001",
+			expected: &scte35.ATSCContentIdentifier{
+				TSID:      0x1234,
+				Reserved:  0x03,
+				UniqueFor: 1,
+				ContentID: []byte{},
+			},
+		},
+		"Invalid End Of Day": {
+			binary: "1234F807414243",
+			err:    scte35.ErrInvalidUPID,
+		},
+		"Invalid Unique For": {
+			binary: "1234CA00414243",
+			err:    scte35.ErrInvalidUPID,
+		},
+		"Too Short": {
+			binary: "1234CA",
+			err:    scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+
+			upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeATSC, b)
+			require.Equal(t, scte35.SegmentationUPIDFormatBase64, upid.Format)
+			require.ErrorIs(t, upid.Validate(), c.err)
+			id, err := upid.ATSCContentIdentifier()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.expected, id)
+			require.Equal(t, b, id.Bytes())
+			require.Equal(t, upid, id.SegmentationUPID())
+		})
+	}
+
+	// text values are still supported
+	upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeATSC, Format: scte35.SegmentationUPIDFormatText, Value: "\x124\u00ca\x07ABC"}
+	id, err := upid.ATSCContentIdentifier()
+	require.NoError(t, err)
+	require.Equal(t, uint32(0x1234), id.TSID)
+
+	// fields are marshalled alongside the value and may be used instead of it
+	upid = scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeATSC, []byte{0x12, 0x34, 0xCA, 0x07, 'A', 'B', 'C'})
+	b, err := json.Marshal(&upid)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"segmentationUpidType":11,"segmentationUpidFormat":"base-64","value":"EjTKB0FCQw==","tsid":4660,"endOfDay":5,"uniqueFor":7,"contentId":"QUJD"}`, string(b))
+	b, err = xml.Marshal(&upid)
+	require.NoError(t, err)
+	require.Equal(t, `<SegmentationUPID segmentationUpidType="11" segmentationUpidFormat="base-64" tsid="4660" endOfDay="5" uniqueFor="7" contentId="QUJD">EjTKB0FCQw==</SegmentationUPID>`, string(b))
+
+	var fromJSON scte35.SegmentationUPID
+	require.NoError(t, json.Unmarshal([]byte(`{"segmentationUpidType":11,"tsid":4660,"endOfDay":5,"uniqueFor":7,"contentId":"QUJD"}`), &fromJSON))
+	require.Equal(t, upid, fromJSON)
+	var fromXML scte35.SegmentationUPID
+	require.NoError(t, xml.Unmarshal([]byte(`<SegmentationUpid segmentationUpidType="11" tsid="4660" endOfDay="5" uniqueFor="7" contentId="QUJD"/>`), &fromXML))
+	require.Equal(t, upid, fromXML)
+}
+
+func TestMID(t *testing.T) {
+	var mid scte35.MID
+	mid.Add(
+		scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeADI, Format: scte35.SegmentationUPIDFormatText, Value: "SIGNAL:abc"},
+		scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABCD0001000H"},
+		scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeURI, Format: scte35.SegmentationUPIDFormatText, Value: "urn:a"},
+		scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeURI, Format: scte35.SegmentationUPIDFormatText, Value: "urn:b"},
+	)
+
+	adID, ok := mid.Find(scte35.SegmentationUPIDTypeAdID)
+	require.True(t, ok)
+	require.Equal(t, "ABCD0001000H", adID.Value)
+	_, ok = mid.Find(scte35.SegmentationUPIDTypeEIDR)
+	require.False(t, ok)
+	require.Len(t, mid.FindAll(scte35.SegmentationUPIDTypeURI), 2)
+	require.Empty(t, mid.FindAll(scte35.SegmentationUPIDTypeEIDR))
+
+	// binary round trip
+	b, err := mid.Bytes()
+	require.NoError(t, err)
+	decoded, err := scte35.DecodeMID(b)
+	require.NoError(t, err)
+	require.Equal(t, mid, decoded)
+
+	// truncated
+	decoded, err = scte35.DecodeMID(b[:len(b)-1])
+	require.ErrorIs(t, err, scte35.ErrInvalidUPID)
+	require.Len(t, decoded, len(mid))
+	upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeMID, b[:len(b)-1])
+	require.ErrorIs(t, upid.Validate(), scte35.ErrInvalidUPID)
+	require.Equal(t, scte35.SegmentationUPIDFormatBase64, upid.Format)
+
+	// nested and flattened descriptors encode identically
+	flat := &scte35.SegmentationDescriptor{SegmentationUPIDs: mid}
+	upid, err = mid.SegmentationUPID()
+	require.NoError(t, err)
+	require.Equal(t, scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeMID, b), upid)
+	nested := &scte35.SegmentationDescriptor{SegmentationUPIDs: []scte35.SegmentationUPID{upid}}
+	require.Equal(t, uint32(scte35.SegmentationUPIDTypeMID), flat.SegmentationUpidType())
+	require.Equal(t, uint32(scte35.SegmentationUPIDTypeMID), nested.SegmentationUpidType())
+	require.Equal(t, len(b), flat.SegmentationUpidLength())
+	require.Equal(t, len(b), nested.SegmentationUpidLength())
+	require.Equal(t, mid, nested.MID())
+
+	// JSON nests the UPIDs within a MID() UPID
+	sd := &scte35.SegmentationDescriptor{SegmentationUPIDs: mid[:2]}
+	b, err = json.Marshal(sd)
+	require.NoError(t, err)
+	require.JSONEq(t, `{
+		"type": 2,
+		"segmentationUpids": [
+			{
+				"segmentationUpidType": 13,
+				"segmentationUpids": [
+					{"segmentationUpidType": 9, "segmentationUpidFormat": "text", "value": "SIGNAL:abc"},
+					{"segmentationUpidType": 3, "segmentationUpidFormat": "text", "value": "ABCD0001000H"}
+				]
+			}
+		]
+	}`, string(b))
+	var fromJSON scte35.SegmentationDescriptor
+	require.NoError(t, json.Unmarshal(b, &fromJSON))
+	require.Equal(t, sd.SegmentationUPIDs, fromJSON.SegmentationUPIDs)
+
+	// XML follows the schema, with a flat sequence of UPIDs
+	b, err = xml.Marshal(sd)
+	require.NoError(t, err)
+	require.Equal(t, `<SegmentationDescriptor xmlns="http://www.scte.org/schemas/35">`+
+		`<SegmentationUpid xmlns="http://www.scte.org/schemas/35" segmentationUpidType="9" segmentationUpidFormat="text">SIGNAL:abc</SegmentationUpid>`+
+		`<SegmentationUpid xmlns="http://www.scte.org/schemas/35" segmentationUpidType="3" segmentationUpidFormat="text">ABCD0001000H</SegmentationUpid>`+
+		`</SegmentationDescriptor>`, string(b))
+	var fromXML scte35.SegmentationDescriptor
+	require.NoError(t, xml.Unmarshal(b, &fromXML))
+	require.Equal(t, sd.SegmentationUPIDs, fromXML.SegmentationUPIDs)
+}
+
+func TestSegmentationUPID_ADI(t *testing.T) {
+	cases := map[string]struct {
+		value    string
+		expected scte35.ADI
+		err      error
+	}{
+		"Signal": {
+			value:    "SIGNAL:Ly9EMGxKR0hFZUtpMHdCUVZnRUFnZz1",
+			expected: scte35.ADI{Element: scte35.ADIElementSignal, Identifier: "Ly9EMGxKR0hFZUtpMHdCUVZnRUFnZz1"},
+		},
+		"Provider Asset": {
+			value:    "MPEG2HD:provider.com/MOVE1234567890123456",
+			expected: scte35.ADI{Element: scte35.ADIElementMPEG2HD, Identifier: "provider.com/MOVE1234567890123456"},
+		},
+		"Missing Element": {
+			value: "provider.com/MOVE1234567890123456",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Unknown Element": {
+			value: "MPEG4:provider.com/MOVE1234567890123456",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Empty Identifier": {
+			value: "PO:",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Non-ASCII Identifier": {
+			value: "PO:café",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeADI, Format: scte35.SegmentationUPIDFormatText, Value: c.value}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			adi, err := upid.ADI()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.expected, adi)
+			require.Equal(t, upid, adi.SegmentationUPID())
+		})
+	}
+}
+
+func TestSegmentationUPID_ADS(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		types []string
+		pos   time.Duration
+		dur   time.Duration
+		tier  uint64
+		err   error
+	}{
+		"All Keys": {
+			value: "type=LA;C3&dur=60000&pos=90000&tier=1",
+			types: []string{scte35.ADSTypeLocalAvailability, scte35.ADSTypeC3},
+			pos:   90 * time.Second,
+			dur:   time.Minute,
+			tier:  1,
+		},
+		"Custom Keys": {
+			value: "availid=914866065&bitmap=&inactivity=3120",
+		},
+		"Unknown Type": {
+			value: "type=XX",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Invalid Duration": {
+			value: "type=LA&dur=1m",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Malformed Pair": {
+			value: "type=LA&dur=60000&tierü0",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Duplicate Key": {
+			value: "type=LA&type=NA",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Empty": {
+			err: scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeADS, Format: scte35.SegmentationUPIDFormatText, Value: c.value}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			ads, err := upid.ADS()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.types, ads.Type())
+			pos, _ := ads.Position()
+			require.Equal(t, c.pos, pos)
+			dur, _ := ads.Duration()
+			require.Equal(t, c.dur, dur)
+			tier, _ := ads.Tier()
+			require.Equal(t, c.tier, tier)
+			require.Equal(t, upid, ads.SegmentationUPID())
+		})
+	}
+
+	// build from scratch
+	ads := scte35.ADS{}
+	ads.Set("type", scte35.ADSTypeNationalReplacement)
+	ads.Set("dur", "30000")
+	ads.Set("type", scte35.ADSTypeNationalAddressable)
+	require.Equal(t, "type=NA&dur=30000", ads.SegmentationUPID().Value)
+}
+
+func TestSegmentationUPID_SCR(t *testing.T) {
+	cases := map[string]struct {
+		value string
+		types []string
+		tier  uint64
+		err   error
+	}{
+		"Program Intermission": {
+			value: "type=PI&tier=1",
+			types: []string{scte35.SCRTypeProgramIntermission},
+			tier:  1,
+		},
+		"Multiple Types": {
+			value: "type=OC;OOC",
+			types: []string{scte35.SCRTypeOpeningCredits, scte35.SCRTypeOverlayedOpeningCredits},
+		},
+		"Unknown Type": {
+			value: "type=LA",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Invalid Tier": {
+			value: "type=PR&tier=-1",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeSCR, Format: scte35.SegmentationUPIDFormatText, Value: c.value}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			scr, err := upid.SCR()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.types, scr.Type())
+			tier, _ := scr.Tier()
+			require.Equal(t, c.tier, tier)
+			require.Equal(t, upid, scr.SegmentationUPID())
+		})
+	}
+}
+
+func TestSegmentationUPID_UUID(t *testing.T) {
+	cases := map[string]struct {
+		value  string
+		binary string
+		err    error
+	}{
+		"Canonical": {
+			value:  "cb0350a9-4877-4ca7-bb63-8730b37a98cf",
+			binary: "CB0350A948774CA7BB638730B37A98CF",
+		},
+		"URN Upper Case": {
+			value:  "urn:uuid:CB0350A9-4877-4CA7-BB63-8730B37A98CF",
+			binary: "CB0350A948774CA7BB638730B37A98CF",
+		},
+		"Missing Hyphens": {
+			value: "cb0350a948774ca7bb638730b37a98cf",
+			err:   scte35.ErrInvalidUPID,
+		},
+		"Invalid Hex": {
+			value: "xb0350a9-4877-4ca7-bb63-8730b37a98cf",
+			err:   scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeUUID, Format: scte35.SegmentationUPIDFormatText, Value: c.value}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			u, err := upid.UUID()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+			require.Equal(t, b, u.Bytes())
+			decoded := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeUUID, b)
+			require.Equal(t, "cb0350a9-4877-4ca7-bb63-8730b37a98cf", decoded.Value)
+			require.Equal(t, decoded, u.SegmentationUPID())
+		})
+	}
+
+	// values that are not 16 bytes are preserved as base-64
+	upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeUUID, []byte{0x01, 0x02})
+	require.Equal(t, scte35.SegmentationUPIDFormatBase64, upid.Format)
+	require.ErrorIs(t, upid.Validate(), scte35.ErrInvalidUPID)
+
+	// raw values encoded as text by earlier releases are still supported
+	legacy := `{
+		"type": 2,
+		"segmentationUpids": [
+			{
+				"segmentationUpidType": 16,
+				"segmentationUpidFormat": "text",
+				"value": "\u00f8\u001dO\u00ae}\u00ec\u0011\u00d0\u00a7e\u0000\u00a0\u00c9\u001ek\u00f6"
+			}
+		]
+	}`
+	var sd scte35.SegmentationDescriptor
+	require.NoError(t, json.Unmarshal([]byte(legacy), &sd))
+	sis := scte35.SpliceInfoSection{
+		SpliceCommand:     scte35.NewTimeSignal(0),
+		SpliceDescriptors: scte35.SpliceDescriptors{&sd},
+		Tier:              4095,
+	}
+	decoded, err := scte35.DecodeBase64(sis.Base64())
+	require.NoError(t, err)
+	require.Equal(t,
+		scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeUUID, Format: scte35.SegmentationUPIDFormatText, Value: "f81d4fae-7dec-11d0-a765-00a0c91e6bf6"},
+		decoded.SpliceDescriptors[0].(*scte35.SegmentationDescriptor).SegmentationUPIDs[0],
+	)
+}
+
+func TestSegmentationUPID_URI(t *testing.T) {
+	cases := map[string]struct {
+		value      string
+		encoded    string
+		err        error
+		encodedErr error
+	}{
+		"URN": {
+			value:   "urn:nbcuni.com:brc:499866434",
+			encoded: "urn:nbcuni.com:brc:499866434",
+		},
+		"URL With Query": {
+			value:   "https://example.com/a/b?c=d&e=%20f#g",
+			encoded: "https://example.com/a/b?c=d&e=%20f#g",
+		},
+		"Non-ASCII": {
+			value:   "https://example.com/café",
+			encoded: "https://example.com/caf%E9",
+			err:     scte35.ErrInvalidUPID,
+		},
+		"Non-Latin-1": {
+			value:   "https://example.com/€",
+			encoded: "https://example.com/%E2%82%AC",
+			err:     scte35.ErrInvalidUPID,
+		},
+		"Relative": {
+			value:      "/a/b",
+			encoded:    "/a/b",
+			err:        scte35.ErrInvalidUPID,
+			encodedErr: scte35.ErrInvalidUPID,
+		},
+		"Invalid Percent-Encoding": {
+			value:   "https://example.com/%G1",
+			encoded: "https://example.com/%25G1",
+			err:     scte35.ErrInvalidUPID,
+		},
+		"Trailing Percent": {
+			value:   "https://example.com/100%",
+			encoded: "https://example.com/100%25",
+			err:     scte35.ErrInvalidUPID,
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			upid := scte35.SegmentationUPID{Type: scte35.SegmentationUPIDTypeURI, Format: scte35.SegmentationUPIDFormatText, Value: c.value}
+			require.ErrorIs(t, upid.Validate(), c.err)
+			_, err := upid.URI()
+			require.ErrorIs(t, err, c.err)
+
+			// invalid values are reported when encoding
+			sis := scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationTypeID: scte35.SegmentationTypeProgramStart,
+						SegmentationUPIDs:  []scte35.SegmentationUPID{upid},
+					},
+				},
+				Tier: 4095,
+			}
+			_, err = sis.Encode()
+			require.ErrorIs(t, err, c.err)
+
+			// encoded values are valid
+			encoded := scte35.EncodeURI(c.value)
+			require.Equal(t, c.encoded, encoded)
+			_, err = scte35.ParseURI(encoded)
+			require.ErrorIs(t, err, c.encodedErr)
+		})
+	}
+
+	// bytes that are not permitted are percent-encoded when decoding
+	for b, value := range map[string]string{
+		"urn:nbcuni.com:brc:499866434":  "urn:nbcuni.com:brc:499866434",
+		"https://example.com/caf%C3%A9": "https://example.com/caf%C3%A9",
+		"https://example.com/caf\xe9":   "https://example.com/caf%E9",
+		"https://example.com/100%":      "https://example.com/100%25",
+	} {
+		upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeURI, []byte(b))
+		require.Equal(t, value, upid.Value)
+		require.NoError(t, upid.Validate())
+
+		sis := scte35.SpliceInfoSection{
+			SpliceCommand: scte35.NewTimeSignal(0),
+			SpliceDescriptors: scte35.SpliceDescriptors{
+				&scte35.SegmentationDescriptor{
+					SegmentationTypeID: scte35.SegmentationTypeProgramStart,
+					SegmentationUPIDs:  []scte35.SegmentationUPID{upid},
+				},
+			},
+			Tier: 4095,
+		}
+		encoded, err := sis.Encode()
+		require.NoError(t, err)
+		require.Contains(t, string(encoded), value)
+		decoded := scte35.SpliceInfoSection{}
+		require.NoError(t, decoded.Decode(encoded))
+		require.Equal(t, sis.SpliceDescriptors, decoded.SpliceDescriptors)
+	}
+}
+
+// counterPayload is a test MPUPayload containing a single counter byte.
+type counterPayload uint8
+
+func (p counterPayload) Bytes() []byte  { return []byte{byte(p)} }
+func (p counterPayload) String() string { return "counter=" + strconv.Itoa(int(p)) }
+
+func TestSegmentationUPID_MPU(t *testing.T) {
+	scte35.RegisterMPUDecoder("CNTR", func(b []byte) (scte35.MPUPayload, error) {
+		if len(b) != 1 {
+			return nil, scte35.ErrInvalidUPID
+		}
+		return counterPayload(b[0]), nil
+	})
+	t.Cleanup(func() { scte35.UnregisterMPUDecoder("CNTR") })
+
+	cases := map[string]struct {
+		binary   string
+		expected *scte35.MPU
+		str      string
+		err      error
+	}{
+		"DISC": {
+			binary:   "44495343594d574630343532303030480a",
+			expected: &scte35.MPU{FormatIdentifier: 0x44495343, PrivateData: scte35.MPUTextPayload("YMWF0452000H\n")},
+			str:      "DISC:YMWF0452000H\n",
+		},
+		"ADFR": {
+			binary: "41444652" + "01" + "0A1B" + "0134D8F5" + "0007" + "001E",
+			expected: &scte35.MPU{FormatIdentifier: 0x41444652, PrivateData: &scte35.MPUADFRPayload{
+				Version:           1,
+				ChannelIdentifier: 0x0A1B,
+				Date:              20240629,
+				BreakCode:         7,
+				Duration:          30,
+			}},
+			str: "ADFR:version=1,channel_identifier=0x0a1b,date=20240629,break_code=7,duration=30",
+		},
+		"ADFR Invalid": {
+			binary: "41444652010A1B",
+			err:    scte35.ErrInvalidUPID,
+		},
+		"Registered": {
+			binary:   "434e545207",
+			expected: &scte35.MPU{FormatIdentifier: 0x434e5452, PrivateData: counterPayload(7)},
+			str:      "CNTR:counter=7",
+		},
+		"Registered Invalid": {
+			binary: "434e54520707",
+			err:    scte35.ErrInvalidUPID,
+		},
+		"Unregistered": {
+			binary:   "414243440102",
+			expected: &scte35.MPU{FormatIdentifier: 0x41424344, PrivateData: scte35.MPURawPayload{0x01, 0x02}},
+			str:      "ABCD:0x0102",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+
+			upid := scte35.NewSegmentationUPID(scte35.SegmentationUPIDTypeMPU, b)
+			require.ErrorIs(t, upid.Validate(), c.err)
+			mpu, err := upid.MPU()
+			require.ErrorIs(t, err, c.err)
+			if err != nil {
+				return
+			}
+			require.Equal(t, c.expected, mpu)
+			require.Equal(t, c.str, mpu.String())
+			require.Equal(t, b, mpu.Bytes())
+			require.Equal(t, upid, mpu.SegmentationUPID())
+		})
+	}
+
+	require.Panics(t, func() { scte35.RegisterMPUDecoder("TOOLONG", scte35.DecodeMPUTextPayload) })
+}
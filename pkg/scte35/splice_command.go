@@ -36,6 +36,7 @@ func NewSpliceCommand(spliceCommandType uint32) SpliceCommand {
 
 // SpliceCommand is an interface for splice_command.
 type SpliceCommand interface {
+	Clone() SpliceCommand
 	Type() uint32
 	decode(b []byte) error
 	encode() ([]byte, error)
@@ -19,6 +19,7 @@ package scte35
 import (
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 
 	"github.com/bamiaux/iobit"
@@ -65,6 +66,7 @@ func NewSpliceDescriptor(identifier uint32, tag uint32) SpliceDescriptor {
 // receiving equipment should skip descriptors with an unknown
 // splice_descriptor_tag.
 type SpliceDescriptor interface {
+	Clone() SpliceDescriptor
 	Tag() uint32
 	decode(b []byte) error
 	encode() ([]byte, error)
@@ -170,7 +172,10 @@ func decodeSpliceDescriptors(b []byte) ([]SpliceDescriptor, error) {
 		sd := NewSpliceDescriptor(identifier, spliceDescriptorTag)
 		if derr := sd.decode(r.Bytes(descriptorLength + 2)); derr != nil {
 			// Store the error but continue decoding the rest of the signal.
-			err = derr
+			// Malformed UPIDs do not mask other errors.
+			if err == nil || errors.Is(err, ErrInvalidUPID) {
+				err = derr
+			}
 		}
 		sds = append(sds, sd)
 	}
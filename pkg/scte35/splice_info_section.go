@@ -23,6 +23,7 @@ import (
 	"encoding/xml"
 	"errors"
 	"fmt"
+	"slices"
 	"time"
 
 	"github.com/bamiaux/iobit"
@@ -66,6 +67,7 @@ type SpliceInfoSection struct {
 	alignmentStuffing   []byte            // alignment_stuffing
 	ecrc32              []byte            // decoded e_crc_32
 	crc32               []byte            // decoded crc_32
+	legacy              bool              // decoded splice_command_length == 0xFFF
 }
 
 // Base64 returns the SpliceInfoSection as a base64 encoded string.
@@ -78,7 +80,29 @@ func (sis *SpliceInfoSection) Base64() string {
 	return base64.StdEncoding.EncodeToString(b)
 }
 
-// Decode the contents of a byte array into this SpliceInfoSection.
+// Clone returns a deep copy of this SpliceInfoSection, including the
+// splice_command, splice_descriptors and any decoded alignment_stuffing and
+// CRC_32 values.
+func (sis *SpliceInfoSection) Clone() *SpliceInfoSection {
+	c := *sis
+	if sis.SpliceCommand != nil {
+		c.SpliceCommand = sis.SpliceCommand.Clone()
+	}
+	if sis.SpliceDescriptors != nil {
+		c.SpliceDescriptors = make(SpliceDescriptors, len(sis.SpliceDescriptors))
+		for i, sd := range sis.SpliceDescriptors {
+			c.SpliceDescriptors[i] = sd.Clone()
+		}
+	}
+	c.alignmentStuffing = slices.Clone(sis.alignmentStuffing)
+	c.ecrc32 = slices.Clone(sis.ecrc32)
+	c.crc32 = slices.Clone(sis.crc32)
+	return &c
+}
+
+// Decode the contents of a byte array into this SpliceInfoSection. Malformed
+// Ad-ID and ISCI UPIDs are decoded in full and reported with an error wrapping
+// ErrInvalidUPID.
 func (sis *SpliceInfoSection) Decode(b []byte) (err error) {
 	r := iobit.NewReader(b)
 	r.Skip(8) // table_id (shall be 0xFC)
@@ -99,6 +123,7 @@ func (sis *SpliceInfoSection) Decode(b []byte) (err error) {
 	switch spliceCommandLength {
 	case 0xFFF:
 		// legacy signal, decode and skip (buffer underflow expected here)
+		sis.legacy = true
 		r2 := r.Peek()
 		sis.SpliceCommand, err = decodeSpliceCommand(spliceCommandType, r2.LeftBytes())
 		if err != nil && !errors.Is(err, ErrBufferUnderflow) {
@@ -115,7 +140,11 @@ func (sis *SpliceInfoSection) Decode(b []byte) (err error) {
 
 	descriptorLoopLength := int(r.Uint32(16)) // bytes
 	sis.SpliceDescriptors, err = decodeSpliceDescriptors(r.Bytes(descriptorLoopLength))
-	if err != nil {
+	var upidErr error
+	if errors.Is(err, ErrInvalidUPID) {
+		// malformed UPIDs are reported once the section is fully decoded
+		upidErr = err
+	} else if err != nil {
 		return err
 	}
 
@@ -141,6 +170,9 @@ func (sis *SpliceInfoSection) Decode(b []byte) (err error) {
 		return fmt.Errorf("splice_info_section: %w", err)
 	}
 
+	if upidErr != nil {
+		return fmt.Errorf("splice_info_section: %w", upidErr)
+	}
 	return nil
 }
 
@@ -236,6 +268,54 @@ func (sis *SpliceInfoSection) Hex() string {
 	return hex.EncodeToString(b)
 }
 
+// LegacySpliceCommandLength returns true if this SpliceInfoSection was decoded
+// from a legacy signal with a splice_command_length of 0xFFF. Encode always
+// writes the actual splice_command_length; see Normalize.
+func (sis *SpliceInfoSection) LegacySpliceCommandLength() bool {
+	return sis.legacy
+}
+
+// Normalize prepares a decoded SpliceInfoSection to be re-encoded as a
+// compliant signal. The legacy splice_command_length indicator and decoded
+// CRC_32 values are cleared and, unless the packet is encrypted, any
+// alignment_stuffing is dropped. The CRC_32 is always recalculated by Encode.
+func (sis *SpliceInfoSection) Normalize() {
+	sis.legacy = false
+	sis.ecrc32 = nil
+	sis.crc32 = nil
+	if !sis.EncryptedPacketFlag() {
+		sis.alignmentStuffing = nil
+	}
+}
+
+// NormalizePTS folds the pts_adjustment into each pts_time carried in the
+// splice_command and sets pts_adjustment to 0. The resulting
+// SpliceInfoSection signals the same splice times.
+func (sis *SpliceInfoSection) NormalizePTS() {
+	adj := int64(sis.PTSAdjustment & maxPTS)
+	sis.forEachSpliceTime(func(st *SpliceTime) {
+		pts := AddPTS(*st.PTSTime, adj)
+		st.PTSTime = &pts
+	})
+	sis.PTSAdjustment = 0
+}
+
+// ShiftPTS adds delta 90KHz ticks to each pts_time carried in the
+// splice_command, wrapping around at 2^33. Use ShiftPTS to move a signal to a
+// new timeline, such as after a transcoder has re-stamped timestamps.
+//
+// The pts_adjustment and any segmentation_descriptor component pts_offset are
+// relative to the pts_time and are left unchanged; call NormalizePTS first to
+// fold the pts_adjustment into the shifted values. Each pts_time is replaced
+// rather than modified in place, so values shared with a shallow copy are not
+// affected.
+func (sis *SpliceInfoSection) ShiftPTS(delta int64) {
+	sis.forEachSpliceTime(func(st *SpliceTime) {
+		pts := AddPTS(*st.PTSTime, delta)
+		st.PTSTime = &pts
+	})
+}
+
 // SAPTypeName returns the Stream Access Point type name.
 func (sis *SpliceInfoSection) SAPTypeName() string {
 	switch sis.SAPType {
@@ -255,38 +335,113 @@ func (sis *SpliceInfoSection) SAPTypeName() string {
 func (sis *SpliceInfoSection) Table(prefix, indent string) string {
 	// top level table is not indented
 	t := newTable(prefix, indent)
+	sis.writeTo(t)
+	return t.String()
+}
+
+// AnnotatedTable returns the tabular description of this SpliceInfoSection
+// with the byte offset (and bit, if not byte aligned) and raw hex of each
+// field in b, which should be the binary this SpliceInfoSection was decoded
+// from. If b is nil the SpliceInfoSection is encoded.
+//
+// Reserved bits, alignment_stuffing and the CRC_32 are included. Fields
+// extending beyond the end of their structure or the signal, lengths that do
+// not match the decoded structure, bytes that were not decoded and an invalid
+// CRC_32 are marked as errors, and highlighted with ANSI escape codes if color
+// is true.
+func (sis *SpliceInfoSection) AnnotatedTable(b []byte, prefix, indent string, color bool) string {
+	if b == nil {
+		b, _ = sis.Encode()
+	}
+	t := newAnnotatedTable(b, prefix, indent, color)
+	sis.writeTo(t)
+	return t.String()
+}
 
+// writeTo writes the tabular description of this SpliceInfoSection to t.
+func (sis *SpliceInfoSection) writeTo(t *table) {
 	t.row(0, "splice_info_section() {", nil)
-	t.row(1, "table_id", fmt.Sprintf("%#02x", TableID))
-	t.row(1, "section_syntax_indicator", SectionSyntaxIndicator)
-	t.row(1, "private_indicator", PrivateIndicator)
-	t.row(1, "sap_type", fmt.Sprintf("%d (%s)", sis.SAPType, sis.SAPTypeName()))
-	t.row(1, "section_length", sis.sectionLength())
+	t.field(1, "table_id", fmt.Sprintf("%#02x", TableID), 8)
+	t.field(1, "section_syntax_indicator", SectionSyntaxIndicator, 1)
+	t.field(1, "private_indicator", PrivateIndicator, 1)
+	t.field(1, "sap_type", fmt.Sprintf("%d (%s)", sis.SAPType, sis.SAPTypeName()), 2)
+	t.begin("section_length", t.length(1, "section_length", sis.sectionLength(), 12))
 	t.row(0, "}", nil)
-	t.row(0, "protocol_version", sis.ProtocolVersion)
-	t.row(0, "encryption_algorithm", fmt.Sprintf("%d (%s)", sis.EncryptedPacket.EncryptionAlgorithm, sis.EncryptedPacket.encryptionAlgorithmName()))
-	t.row(0, "pts_adjustment", sis.PTSAdjustment)
-	t.row(0, "cw_index", sis.EncryptedPacket.CWIndex)
-	t.row(0, "tier", sis.Tier)
-
-	if sis.SpliceCommand != nil {
-		t.row(0, "splice_command_length", sis.SpliceCommand.length())
-		t.row(0, "splice_command_type", fmt.Sprintf("%#02x", sis.SpliceCommand.Type()))
+	t.field(0, "protocol_version", sis.ProtocolVersion, 8)
+	encryptedPacket, _ := t.peek(1)
+	t.hidden(0, "encrypted_packet", encryptedPacket == 1, 1)
+	t.field(0, "encryption_algorithm", fmt.Sprintf("%d (%s)", sis.EncryptedPacket.EncryptionAlgorithm, sis.EncryptedPacket.encryptionAlgorithmName()), 6)
+	t.field(0, "pts_adjustment", sis.PTSAdjustment, 33)
+	t.field(0, "cw_index", sis.EncryptedPacket.CWIndex, 8)
+	t.field(0, "tier", sis.Tier, 12)
+
+	switch {
+	case sis.SpliceCommand != nil:
+		if sis.legacy {
+			t.field(0, "splice_command_length", fmt.Sprintf("%#x (legacy, actual %d)", 0xFFF, sis.SpliceCommand.length()), 12)
+			t.field(0, "splice_command_type", fmt.Sprintf("%#02x", sis.SpliceCommand.Type()), 8)
+			t.begin("splice_command_length", sis.SpliceCommand.length())
+		} else {
+			length := t.length(0, "splice_command_length", sis.SpliceCommand.length(), 12)
+			t.field(0, "splice_command_type", fmt.Sprintf("%#02x", sis.SpliceCommand.Type()), 8)
+			t.begin("splice_command_length", length)
+		}
 		sis.SpliceCommand.writeTo(t)
+		t.end(1)
+	case t.annotated:
+		t.begin("splice_command_length", t.length(0, "splice_command_length", 0, 12))
+		t.hidden(0, "splice_command_type", nil, 8)
+		t.end(1)
 	}
 
-	t.row(0, "descriptor_loop_length", sis.descriptorLoopLength())
+	t.begin("descriptor_loop_length", t.length(0, "descriptor_loop_length", sis.descriptorLoopLength(), 16))
 	for _, sd := range sis.SpliceDescriptors {
 		sd.writeTo(t)
 	}
-	return t.String()
+	t.end(1)
+
+	if t.annotated {
+		// the remainder of the section
+		trailer := (t.scopes[len(t.scopes)-1].end - t.pos) / 8
+		crcLen := 4
+		if encryptedPacket == 1 {
+			crcLen += 4
+		}
+		if n := trailer - crcLen; n > 0 {
+			t.hidden(0, "alignment_stuffing", nil, n*8)
+		}
+		if encryptedPacket == 1 {
+			t.hidden(0, "E_CRC_32", nil, 32)
+		}
+		sis.writeCRC32(t)
+		t.end(0)
+		t.end(0)
+	}
+}
+
+// writeCRC32 describes the CRC_32 in an annotated table, reporting an error if
+// it does not match the calculated value.
+func (sis *SpliceInfoSection) writeCRC32(t *table) {
+	crc, ok := t.peek(32)
+	if !ok {
+		t.hidden(0, "CRC_32", nil, 32)
+		return
+	}
+	calculated := calculateCRC32(t.raw[:t.pos/8])
+	if uint32(crc) != calculated {
+		t.annotate(0, "CRC_32", fmt.Sprintf("%#08x", crc), 32, fmt.Sprintf("calculated CRC_32 is %#08x", calculated))
+		return
+	}
+	t.hidden(0, "CRC_32", fmt.Sprintf("%#08x", crc), 32)
 }
 
 // MarshalJSON encodes a SpliceInfoSection to JSON.
 func (sis *SpliceInfoSection) MarshalJSON() ([]byte, error) {
 	// ensure JSONTypes are all set before marshalling. These are included in
 	// each SpliceCommand.Type() and SpliceDescriptor.Tag() implementation.
-	sis.SpliceCommand.Type()
+	if sis.SpliceCommand != nil {
+		sis.SpliceCommand.Type()
+	}
 	for i := range sis.SpliceDescriptors {
 		sis.SpliceDescriptors[i].Tag()
 	}
@@ -350,6 +505,26 @@ func (sis *SpliceInfoSection) UnmarshalXML(d *xml.Decoder, start xml.StartElemen
 	return nil
 }
 
+// forEachSpliceTime calls f for each specified splice_time() in the
+// splice_command.
+func (sis *SpliceInfoSection) forEachSpliceTime(f func(st *SpliceTime)) {
+	switch sc := sis.SpliceCommand.(type) {
+	case *TimeSignal:
+		if sc.SpliceTime.TimeSpecifiedFlag() {
+			f(&sc.SpliceTime)
+		}
+	case *SpliceInsert:
+		if sc.Program != nil && sc.Program.SpliceTime.TimeSpecifiedFlag() {
+			f(&sc.Program.SpliceTime)
+		}
+		for i := range sc.Components {
+			if sc.Components[i].SpliceTime.TimeSpecifiedFlag() {
+				f(sc.Components[i].SpliceTime)
+			}
+		}
+	}
+}
+
 // length returns the expected length of the encoded splice_info_section, in
 // bytes.
 func (sis *SpliceInfoSection) length() int {
@@ -1,8 +1,10 @@
 package scte35_test
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
+	"strings"
 	"testing"
 
 	"github.com/Comcast/scte35-go/pkg/scte35"
@@ -241,6 +243,68 @@ func TestSpliceInfoSection_UnmarshalJSON(t *testing.T) {
 				},
 			},
 		},
+		"Nested MID": {
+			json: `{
+				"spliceCommand": {
+					"type": 6,
+					"spliceTime": {
+						"ptsTime": 1924989008
+					}
+				},
+				"spliceDescriptors": [
+					{
+						"type": 2,
+						"segmentationUpids": [
+							{
+								"segmentationUpidType": 13,
+								"segmentationUpids": [
+									{
+										"segmentationUpidType": 3,
+										"segmentationUpidFormat": "text",
+										"value": "ABCD0001000H"
+									},
+									{
+										"segmentationUpidType": 8,
+										"segmentationUpidFormat": "text",
+										"value": "748724618"
+									}
+								]
+							}
+						],
+						"segmentationEventId": 1207959694,
+						"segmentationTypeId": 52,
+						"segmentNum": 2
+					}
+				],
+				"tier": 4095
+			}`,
+			expected: &scte35.SpliceInfoSection{
+				Tier:    uint32(4095),
+				SAPType: scte35.SAPTypeNotSpecified,
+				SpliceCommand: &scte35.TimeSignal{
+					SpliceTime: scte35.SpliceTime{PTSTime: ptr(uint64(1924989008))},
+				},
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationUPIDs: []scte35.SegmentationUPID{
+							{
+								Type:   scte35.SegmentationUPIDTypeAdID,
+								Format: scte35.SegmentationUPIDFormatText,
+								Value:  "ABCD0001000H",
+							},
+							{
+								Type:   scte35.SegmentationUPIDTypeTI,
+								Format: scte35.SegmentationUPIDFormatText,
+								Value:  "748724618",
+							},
+						},
+						SegmentationEventID: uint32(1207959694),
+						SegmentationTypeID:  scte35.SegmentationTypeProviderPOStart,
+						SegmentNum:          2,
+					},
+				},
+			},
+		},
 	}
 
 	for k, c := range cases {
@@ -251,3 +315,351 @@ func TestSpliceInfoSection_UnmarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+func TestSpliceInfoSection_Clone(t *testing.T) {
+	cases := map[string]struct {
+		binary string
+		sis    *scte35.SpliceInfoSection
+	}{
+		"Sample 14.1 time_signal - Placement Opportunity Start": {
+			binary: "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg==",
+		},
+		"SpliceInsert Program Out Point with 3 bytes alignment stuffing": {
+			binary: "/DA0AABS2+YAAACgFAUALJGCf+/+MSwPcX4AUmXAAAAAAAAMAQpDVUVJRp8xMjEq3pnIPCi6lw==",
+		},
+		"SpliceInsert Components": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.SpliceInsert{
+					BreakDuration: &scte35.BreakDuration{AutoReturn: true, Duration: 0x052ccf5},
+					Components: []scte35.SpliceInsertComponent{
+						{Tag: 1, SpliceTime: &scte35.SpliceTime{PTSTime: ptr(uint64(0x07369c02e))}},
+					},
+					SpliceEventID: 0x4800008f,
+				},
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						SegmentationEventID: 1,
+						SegmentationTypeID:  scte35.SegmentationTypeProviderPOStart,
+						SegmentationUPIDs: []scte35.SegmentationUPID{
+							{Type: scte35.SegmentationUPIDTypeMPU, FormatIdentifier: ptr(uint32(0x44495343)), Format: scte35.SegmentationUPIDFormatBase64, Value: "YWJj"},
+						},
+						Components: []scte35.SegmentationDescriptorComponent{
+							{Tag: 1, PTSOffset: 1},
+						},
+						SubSegmentNum:       ptr(uint32(1)),
+						SubSegmentsExpected: ptr(uint32(2)),
+					},
+					&scte35.PrivateDescriptor{Identifier: 0x41424344, PrivateTag: 1, PrivateBytes: []byte{0x01}},
+				},
+				Tier:    4095,
+				SAPType: scte35.SAPTypeNotSpecified,
+			},
+		},
+		"PrivateCommand": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.PrivateCommand{Identifier: 0x41424344, PrivateBytes: []byte{0x01}},
+				Tier:          4095,
+				SAPType:       scte35.SAPTypeNotSpecified,
+			},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis := c.sis
+			if c.binary != "" {
+				var err error
+				sis, err = scte35.DecodeBase64(c.binary)
+				require.NoError(t, err)
+			}
+			expected := toJSON(sis)
+			expectedBase64 := sis.Base64()
+
+			clone := sis.Clone()
+			require.True(t, scte35.Equal(sis, clone))
+			require.Equal(t, expectedBase64, clone.Base64())
+
+			// mutating the clone must not affect the original
+			mutateSpliceInfoSection(clone)
+			require.False(t, scte35.Equal(sis, clone))
+			require.Equal(t, expected, toJSON(sis))
+			require.Equal(t, expectedBase64, sis.Base64())
+		})
+	}
+}
+
+// mutateSpliceInfoSection modifies every pointer, slice and nested value in
+// sis.
+func mutateSpliceInfoSection(sis *scte35.SpliceInfoSection) {
+	sis.Tier = 0x100
+	switch sc := sis.SpliceCommand.(type) {
+	case *scte35.TimeSignal:
+		*sc.SpliceTime.PTSTime++
+	case *scte35.SpliceInsert:
+		if sc.Program != nil {
+			*sc.Program.SpliceTime.PTSTime++
+		}
+		for i := range sc.Components {
+			*sc.Components[i].SpliceTime.PTSTime++
+		}
+		if sc.BreakDuration != nil {
+			sc.BreakDuration.Duration++
+		}
+	case *scte35.PrivateCommand:
+		sc.PrivateBytes[0]++
+	}
+	for _, sd := range sis.SpliceDescriptors {
+		switch sdt := sd.(type) {
+		case *scte35.SegmentationDescriptor:
+			if sdt.DeliveryRestrictions != nil {
+				sdt.DeliveryRestrictions.DeviceRestrictions = scte35.DeviceRestrictionsGroup0
+			}
+			if sdt.SegmentationDuration != nil {
+				*sdt.SegmentationDuration++
+			}
+			if sdt.SubSegmentNum != nil {
+				*sdt.SubSegmentNum++
+			}
+			for i := range sdt.SegmentationUPIDs {
+				sdt.SegmentationUPIDs[i].Value = "mutated"
+				if sdt.SegmentationUPIDs[i].FormatIdentifier != nil {
+					*sdt.SegmentationUPIDs[i].FormatIdentifier++
+				}
+			}
+			for i := range sdt.Components {
+				sdt.Components[i].PTSOffset++
+			}
+		case *scte35.PrivateDescriptor:
+			sdt.PrivateBytes[0]++
+		}
+	}
+}
+
+func TestSpliceInfoSection_ShiftPTS(t *testing.T) {
+	cases := map[string]struct {
+		sis       *scte35.SpliceInfoSection
+		delta     int64
+		normalize bool
+		expected  *scte35.SpliceInfoSection
+	}{
+		"TimeSignal": {
+			sis:      &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x072bd0050), PTSAdjustment: 10},
+			delta:    -0x50,
+			expected: &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x072bd0000), PTSAdjustment: 10},
+		},
+		"TimeSignal Wraparound": {
+			sis:      &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x1FFFFFFF0)},
+			delta:    0x20,
+			expected: &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x10)},
+		},
+		"TimeSignal Normalize": {
+			sis:       &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x1FFFFFFF0), PTSAdjustment: 0x20},
+			delta:     0x10,
+			normalize: true,
+			expected:  &scte35.SpliceInfoSection{SpliceCommand: scte35.NewTimeSignal(0x20)},
+		},
+		"TimeSignal Immediate": {
+			sis:       &scte35.SpliceInfoSection{SpliceCommand: &scte35.TimeSignal{}, PTSAdjustment: 0x20},
+			delta:     0x10,
+			normalize: true,
+			expected:  &scte35.SpliceInfoSection{SpliceCommand: &scte35.TimeSignal{}},
+		},
+		"SpliceInsert": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.SpliceInsert{
+					Program: scte35.NewSpliceInsertProgram(100),
+					Components: []scte35.SpliceInsertComponent{
+						{Tag: 1, SpliceTime: &scte35.SpliceTime{PTSTime: ptr(uint64(200))}},
+						{Tag: 2, SpliceTime: &scte35.SpliceTime{}},
+						{Tag: 3},
+					},
+					BreakDuration: &scte35.BreakDuration{Duration: 300},
+				},
+				PTSAdjustment: 5,
+			},
+			delta:     -150,
+			normalize: true,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: &scte35.SpliceInsert{
+					Program: scte35.NewSpliceInsertProgram(0x1FFFFFFFF - 44),
+					Components: []scte35.SpliceInsertComponent{
+						{Tag: 1, SpliceTime: &scte35.SpliceTime{PTSTime: ptr(uint64(55))}},
+						{Tag: 2, SpliceTime: &scte35.SpliceTime{}},
+						{Tag: 3},
+					},
+					BreakDuration: &scte35.BreakDuration{Duration: 300},
+				},
+			},
+		},
+		"Segmentation Component PTS Offset": {
+			sis: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						Components: []scte35.SegmentationDescriptorComponent{{Tag: 1, PTSOffset: 10}},
+					},
+				},
+			},
+			delta: 100,
+			expected: &scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(200),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{
+						Components: []scte35.SegmentationDescriptorComponent{{Tag: 1, PTSOffset: 10}},
+					},
+				},
+			},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis := c.sis
+			if c.normalize {
+				sis.NormalizePTS()
+			}
+			sis.ShiftPTS(c.delta)
+			require.Empty(t, scte35.Diff(c.expected, sis))
+		})
+	}
+}
+
+func TestSpliceInfoSection_Normalize(t *testing.T) {
+	cases := map[string]struct {
+		binary   string
+		legacy   bool
+		expected string
+	}{
+		"Legacy splice_command_length: 0xFFF": {
+			binary:   "/DA8AAAAAAAAAP///wb+06ACpQAmAiRDVUVJAACcHX//AACky4AMEERJU0NZTVdGMDQ1MjAwMEgxAQEMm4c0",
+			legacy:   true,
+			expected: "/DA8AAAAAAAAAP/wBQb+06ACpQAmAiRDVUVJAACcHX//AACky4AMEERJU0NZTVdGMDQ1MjAwMEgxAQE9z6dN",
+		},
+		"Legacy Alignment Stuffing": {
+			binary:   "/DAeAAAAAAAAAP///wViAA/nf18ACQAAAAAskJv+YPtE",
+			legacy:   true,
+			expected: "/DAbAAAAAAAAAP/wCgViAA/nf18ACQAAAAD3AIF3",
+		},
+		"Alignment Stuffing": {
+			binary:   "/DA0AABS2+YAAACgFAUALJGCf+/+MSwPcX4AUmXAAAAAAAAMAQpDVUVJRp8xMjEq3pnIPCi6lw==",
+			expected: "/DAxAABS2+YAAACgFAUALJGCf+/+MSwPcX4AUmXAAAAAAAAMAQpDVUVJRp8xMjEqG/cEbQ==",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			sis, err := scte35.DecodeBase64(c.binary)
+			require.NoError(t, err)
+			require.Equal(t, c.legacy, sis.LegacySpliceCommandLength())
+
+			normalized := sis.Clone()
+			normalized.Normalize()
+			require.False(t, normalized.LegacySpliceCommandLength())
+			require.True(t, scte35.Equal(sis, normalized))
+			require.Equal(t, c.expected, normalized.Base64())
+
+			// re-decoding is no longer legacy
+			decoded, err := scte35.DecodeBase64(normalized.Base64())
+			require.NoError(t, err)
+			require.False(t, decoded.LegacySpliceCommandLength())
+			require.True(t, scte35.Equal(sis, decoded))
+		})
+	}
+}
+
+func TestSpliceInfoSection_AnnotatedTable(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "fc3034000000000000fffff00506fe72bd0050001e021c435545494800008e7fcf0001a599b00808000000002ca0a18a3402009ac9d17e"
+
+	cases := map[string]struct {
+		binary   string
+		encode   bool
+		color    bool
+		errors   int
+		contains []string
+	}{
+		"Valid": {
+			binary: signal,
+			contains: []string{
+				"0000    fc                \ttable_id: 0xfc\n",
+				"0001.4  3034              \tsection_length: 52\n",
+				"000e.1  fe                \treserved: 111111\n",
+				"000e.7  fe72bd0050        \tpts_time: 1924989008\n",
+				"0027    08                \tsegmentation_upid_length: 8\n",
+				"0026    08                \t\tsegmentation_upid_type: 0x08 (TI)\n",
+				"0028    000000002ca0a18a  \t\tsegmentation_upid: 748724618\n",
+				"0033    9ac9d17e          CRC_32: 0x9ac9d17e\n",
+			},
+		},
+		"Encoded": {
+			encode: true,
+			contains: []string{
+				"0013    001e              descriptor_loop_length: 30\n",
+				"0033    9ac9d17e          CRC_32: 0x9ac9d17e\n",
+			},
+		},
+		"Invalid CRC_32": {
+			binary: signal[:len(signal)-2] + "7f",
+			errors: 1,
+			contains: []string{
+				"0033    9ac9d17f          CRC_32: 0x9ac9d17f  <- error: calculated CRC_32 is 0x9ac9d17e\n",
+			},
+		},
+		"Truncated": {
+			binary: signal[:60],
+			errors: 1,
+			contains: []string{
+				"0001.4  3034              \tsection_length: 52\n",
+				"001b    480000            \tsegmentation_event_id: 1207959552  <- error: extends beyond the end of the signal\n",
+				"001f    --                \tsegmentation_event_cancel_indicator: false\n",
+			},
+		},
+		"Invalid splice_command_length": {
+			binary: signal[:24] + "06" + signal[26:],
+			errors: 3,
+			contains: []string{
+				"000b.4  f006              splice_command_length: 6\n",
+				"0013    00                \t(unused): 1 byte  <- error: not decoded\n",
+				"0014    1e02              descriptor_loop_length: 7682  <- error: length extends beyond section_length\n",
+			},
+		},
+		"Trailing Bytes": {
+			binary: signal + "ffff",
+			errors: 1,
+			contains: []string{
+				"0037    ffff              (unused): 2 bytes  <- error: not decoded\n",
+			},
+		},
+		"Color": {
+			binary: signal[:len(signal)-2] + "7f",
+			color:  true,
+			errors: 1,
+			contains: []string{
+				"\x1b[2m0000    \x1b[0m\x1b[36mfc                \x1b[0m\ttable_id: 0xfc\n",
+				"\x1b[1;31m  <- error: calculated CRC_32 is 0x9ac9d17e\x1b[0m\n",
+			},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			b, err := hex.DecodeString(c.binary)
+			require.NoError(t, err)
+
+			sis := &scte35.SpliceInfoSection{}
+			if c.encode {
+				sis, err = scte35.DecodeHex(signal)
+				require.NoError(t, err)
+				b = nil
+			} else {
+				_ = sis.Decode(b)
+			}
+
+			table := sis.AnnotatedTable(b, "", "\t", c.color)
+			require.Equal(t, c.errors, strings.Count(table, "<- error"), table)
+			for _, s := range c.contains {
+				require.Contains(t, table, s)
+			}
+		})
+	}
+}
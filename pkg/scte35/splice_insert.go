@@ -47,6 +47,26 @@ type SpliceInsert struct {
 	AvailsExpected             uint32                  `xml:"availsExpected,attr" json:"availsExpected,omitempty"`
 }
 
+// Clone returns a deep copy of this SpliceInsert.
+func (cmd *SpliceInsert) Clone() SpliceCommand {
+	c := *cmd
+	if cmd.Program != nil {
+		c.Program = &SpliceInsertProgram{SpliceTime: cmd.Program.SpliceTime.clone()}
+	}
+	if cmd.Components != nil {
+		c.Components = make([]SpliceInsertComponent, len(cmd.Components))
+		for i, sic := range cmd.Components {
+			c.Components[i] = SpliceInsertComponent{Tag: sic.Tag}
+			if sic.SpliceTime != nil {
+				st := sic.SpliceTime.clone()
+				c.Components[i].SpliceTime = &st
+			}
+		}
+	}
+	c.BreakDuration = clonePtr(cmd.BreakDuration)
+	return &c
+}
+
 // DurationFlag returns the duration_flag.
 func (cmd *SpliceInsert) DurationFlag() bool {
 	return cmd.BreakDuration != nil
@@ -71,40 +91,49 @@ func (cmd *SpliceInsert) Type() uint32 {
 // writeTo the given table.
 func (cmd *SpliceInsert) writeTo(t *table) {
 	t.row(0, "splice_insert() {", nil)
-	t.row(1, "splice_event_id", cmd.SpliceEventID)
-	t.row(1, "splice_event_cancel_indicator", cmd.SpliceEventCancelIndicator)
+	t.field(1, "splice_event_id", cmd.SpliceEventID, 32)
+	t.field(1, "splice_event_cancel_indicator", cmd.SpliceEventCancelIndicator, 1)
+	t.reserved(1, 7)
 	if !cmd.SpliceEventCancelIndicator {
-		t.row(1, "out_of_network_indicator", cmd.OutOfNetworkIndicator)
-		t.row(1, "program_splice_flag", cmd.ProgramSpliceFlag())
-		t.row(1, "duration_flag", cmd.DurationFlag())
-		t.row(1, "splice_immediate_flag", cmd.SpliceImmediateFlag)
+		t.field(1, "out_of_network_indicator", cmd.OutOfNetworkIndicator, 1)
+		t.field(1, "program_splice_flag", cmd.ProgramSpliceFlag(), 1)
+		t.field(1, "duration_flag", cmd.DurationFlag(), 1)
+		t.field(1, "splice_immediate_flag", cmd.SpliceImmediateFlag, 1)
+		t.reserved(1, 4)
 		if cmd.ProgramSpliceFlag() && !cmd.SpliceImmediateFlag {
-			t.row(1, "time_specified_flag", cmd.TimeSpecifiedFlag())
+			t.field(1, "time_specified_flag", cmd.TimeSpecifiedFlag(), 1)
 			if cmd.TimeSpecifiedFlag() {
-				t.row(1, "pts_time", cmd.Program.SpliceTime.PTSTime)
+				t.reserved(1, 6)
+				t.field(1, "pts_time", cmd.Program.SpliceTime.PTSTime, 33)
+			} else {
+				t.reserved(1, 7)
 			}
 		}
 		if !cmd.ProgramSpliceFlag() {
-			t.row(1, "component_count", len(cmd.Components))
+			t.field(1, "component_count", len(cmd.Components), 8)
 			for i, c := range cmd.Components {
 				t.row(1, "component["+strconv.Itoa(i)+"]", nil)
-				t.row(2, "component_tag", c.Tag)
+				t.field(2, "component_tag", c.Tag, 8)
 				if !cmd.SpliceImmediateFlag {
-					t.row(2, "time_specified_flag", c.TimeSpecifiedFlag())
+					t.field(2, "time_specified_flag", c.TimeSpecifiedFlag(), 1)
 					if c.TimeSpecifiedFlag() {
-						t.row(2, "pts_time", c.SpliceTime.PTSTime)
+						t.reserved(2, 6)
+						t.field(2, "pts_time", c.SpliceTime.PTSTime, 33)
+					} else {
+						t.reserved(2, 7)
 					}
 				}
 				t.row(1, "}", nil)
 			}
 		}
 		if cmd.DurationFlag() {
-			t.row(1, "auto_return", cmd.BreakDuration.AutoReturn)
-			t.row(1, "duration", cmd.BreakDuration.Duration)
+			t.field(1, "auto_return", cmd.BreakDuration.AutoReturn, 1)
+			t.reserved(1, 6)
+			t.field(1, "duration", cmd.BreakDuration.Duration, 33)
 		}
-		t.row(1, "unique_program_id", cmd.UniqueProgramID)
-		t.row(1, "avail_num", cmd.AvailNum)
-		t.row(1, "avails_expected", cmd.AvailsExpected)
+		t.field(1, "unique_program_id", cmd.UniqueProgramID, 16)
+		t.field(1, "avail_num", cmd.AvailNum, 8)
+		t.field(1, "avails_expected", cmd.AvailsExpected, 8)
 	}
 	t.row(0, "}", nil)
 }
@@ -36,6 +36,12 @@ type SpliceNull struct {
 	JSONType uint32   `xml:"-" json:"type"`
 }
 
+// Clone returns a deep copy of this SpliceNull.
+func (cmd *SpliceNull) Clone() SpliceCommand {
+	c := *cmd
+	return &c
+}
+
 // Type returns the splice_command_type.
 func (cmd *SpliceNull) Type() uint32 {
 	// ensure JSONType is set
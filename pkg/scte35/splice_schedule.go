@@ -19,6 +19,7 @@ package scte35
 import (
 	"encoding/xml"
 	"fmt"
+	"slices"
 	"strconv"
 
 	"github.com/bamiaux/iobit"
@@ -38,6 +39,21 @@ type SpliceSchedule struct {
 	Events   []Event  `xml:"http://www.scte.org/schemas/35 Event" json:"events"`
 }
 
+// Clone returns a deep copy of this SpliceSchedule.
+func (cmd *SpliceSchedule) Clone() SpliceCommand {
+	c := *cmd
+	if cmd.Events != nil {
+		c.Events = make([]Event, len(cmd.Events))
+		for i, e := range cmd.Events {
+			e.Program = clonePtr(e.Program)
+			e.Components = slices.Clone(e.Components)
+			e.BreakDuration = clonePtr(e.BreakDuration)
+			c.Events[i] = e
+		}
+	}
+	return &c
+}
+
 // Type returns the splice_command_type
 func (cmd *SpliceSchedule) Type() uint32 {
 	// ensure the JSONType is set correctly.
@@ -184,35 +200,44 @@ func (cmd SpliceSchedule) length() int {
 // writeTo the given table.
 func (cmd *SpliceSchedule) writeTo(t *table) {
 	t.row(0, "splice_schedule() {", nil)
-	t.row(1, "splice_count", strconv.Itoa(len(cmd.Events)))
+	t.field(1, "splice_count", strconv.Itoa(len(cmd.Events)), 8)
 	for i, e := range cmd.Events {
 		t.row(1, "event["+strconv.Itoa(i)+"]", nil)
-		t.row(2, "splice_event_id", e.SpliceEventID)
-		t.row(2, "splice_event_cancel_indicator", e.SpliceEventCancelIndicator)
-		t.row(2, "event_id_compliance_flag", e.EventIDComplianceFlag)
+		t.field(2, "splice_event_id", e.SpliceEventID, 32)
+		t.field(2, "splice_event_cancel_indicator", e.SpliceEventCancelIndicator, 1)
+		t.field(2, "event_id_compliance_flag", e.EventIDComplianceFlag, 1)
+		t.reserved(2, 6)
 		if !e.SpliceEventCancelIndicator {
-			t.row(2, "out_of_network_indicator", e.OutOfNetworkIndicator)
-			t.row(2, "program_splice_flag", e.ProgramSpliceFlag())
-			t.row(2, "duration_flag", e.DurationFlag())
+			t.field(2, "out_of_network_indicator", e.OutOfNetworkIndicator, 1)
+			t.field(2, "program_splice_flag", e.ProgramSpliceFlag(), 1)
+			t.field(2, "duration_flag", e.DurationFlag(), 1)
+			t.reserved(2, 5)
 			if e.ProgramSpliceFlag() {
-				t.row(2, "utc_splice_time", e.Program.UTCSpliceTime)
+				t.field(2, "utc_splice_time", e.Program.UTCSpliceTime, 32)
 			} else {
-				t.row(2, "component_count", len(e.Components))
+				t.field(2, "component_count", len(e.Components), 8)
 				for j, c := range e.Components {
 					t.row(2, "component["+strconv.Itoa(j)+"]", nil)
-					t.row(3, "component_tag", c.Tag)
-					t.row(3, "utc_splice_time", c.UTCSpliceTime)
+					t.field(3, "component_tag", c.Tag, 8)
+					t.field(3, "utc_splice_time", c.UTCSpliceTime, 32)
 					t.row(2, "}", nil)
 				}
 			}
 			if e.DurationFlag() {
-				t.row(1, "auto_return", e.BreakDuration.AutoReturn)
-				t.row(1, "duration", e.BreakDuration.Duration)
+				t.field(1, "auto_return", e.BreakDuration.AutoReturn, 1)
+				t.reserved(1, 6)
+				t.field(1, "duration", e.BreakDuration.Duration, 33)
 			}
-			t.row(1, "unique_program_id", e.UniqueProgramID)
-			t.row(1, "avail_num", e.AvailNum)
-			t.row(1, "avails_expected", e.AvailsExpected)
 		}
+		// unique_program_id, avail_num and avails_expected are encoded even if
+		// the event is cancelled, but only shown in annotated tables
+		field := t.field
+		if e.SpliceEventCancelIndicator {
+			field = t.hidden
+		}
+		field(1, "unique_program_id", e.UniqueProgramID, 16)
+		field(1, "avail_num", e.AvailNum, 8)
+		field(1, "avails_expected", e.AvailsExpected, 8)
 		t.row(1, "}", nil)
 	}
 	t.row(0, "}", nil)
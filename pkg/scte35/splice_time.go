@@ -25,3 +25,8 @@ type SpliceTime struct {
 func (t *SpliceTime) TimeSpecifiedFlag() bool {
 	return t != nil && t.PTSTime != nil
 }
+
+// clone returns a deep copy of this SpliceTime.
+func (t SpliceTime) clone() SpliceTime {
+	return SpliceTime{PTSTime: clonePtr(t.PTSTime)}
+}
@@ -5,6 +5,8 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/bamiaux/iobit"
 )
 
 // newTable creates a new table with the given parameters.
@@ -16,16 +18,226 @@ func newTable(prefix, indent string) *table {
 	}
 }
 
+// newAnnotatedTable creates a new table annotating each field with its offset
+// and raw bytes in b.
+func newAnnotatedTable(b []byte, prefix, indent string, color bool) *table {
+	t := newTable(prefix, indent)
+	t.annotated = true
+	t.raw = b
+	t.color = color
+	t.scopes = []tableScope{{name: "the end of the signal", end: len(b) * 8}}
+	return t
+}
+
+// ANSI escape codes used by colorized annotated tables.
+const (
+	ansiReset = "\x1b[0m"
+	ansiDim   = "\x1b[2m"
+	ansiCyan  = "\x1b[36m"
+	ansiRed   = "\x1b[1;31m"
+)
+
+// annotationWidth is the width of the offset and hex columns of an annotated
+// table, and annotationBytes the number of bytes shown in the hex column.
+const (
+	annotationWidth = 26
+	annotationBytes = 8
+)
+
 // table simplifies construction of splice_info_section tables.
 type table struct {
 	prefix string
 	indent string
 	b      *strings.Builder
+
+	// annotated tables include the offset and raw bytes of each field
+	annotated bool
+	raw       []byte
+	color     bool
+	// pos is the bit offset of the next field
+	pos int
+	// scopes are the structures enclosing the next field, innermost last
+	scopes []tableScope
 }
 
-// row0 writes a new row with 0 indents.
+// tableScope is a structure whose fields must not extend beyond end.
+type tableScope struct {
+	// name is the field bounding the structure, used in error messages
+	name       string
+	end        int
+	overflowed bool
+}
+
+// row writes a new row that does not describe a field, such as the start or
+// end of a structure or a value derived from other fields.
 func (t *table) row(indents int, key string, value any) {
+	if t.annotated {
+		_, _ = t.b.WriteString(t.prefix)
+		_, _ = t.b.WriteString(strings.Repeat(" ", annotationWidth))
+		t.writeRow("", indents, key, value, "")
+		return
+	}
+	t.writeRow(t.prefix, indents, key, value, "")
+}
+
+// field writes a new row describing a field of the given size in bits. A
+// size of 0 indicates a value that is not present in the binary form.
+func (t *table) field(indents int, key string, value any, bits int) {
+	if !t.annotated || bits == 0 {
+		t.row(indents, key, value)
+		return
+	}
+	t.annotate(indents, key, value, bits, "")
+}
+
+// hidden describes a field of the given size in bits that is only shown in
+// annotated tables, such as reserved bits or the CRC_32.
+func (t *table) hidden(indents int, key string, value any, bits int) {
+	if t.annotated && bits > 0 {
+		t.annotate(indents, key, value, bits, "")
+	}
+}
+
+// reserved describes reserved bits, which are only shown in annotated tables.
+func (t *table) reserved(indents int, bits int) {
+	if !t.annotated {
+		return
+	}
+	var value any
+	if v, ok := t.peek(bits); ok {
+		value = fmt.Sprintf("%0*b", bits, v)
+	}
+	t.annotate(indents, "reserved", value, bits, "")
+}
+
+// length writes a new row describing a length field of the given size in
+// bits, returning its value. Annotated tables show and return the encoded
+// value, so that a structure whose fields do not match its length is
+// reported by its fields (see begin), and report a length extending beyond
+// the enclosing structure.
+func (t *table) length(indents int, key string, length int, bits int) int {
+	if !t.annotated {
+		t.row(indents, key, length)
+		return length
+	}
+	if v, ok := t.peek(bits); ok {
+		length = int(v)
+	}
+	var msg string
+	// a truncated signal is reported by its first missing field instead
+	if len(t.scopes) > 1 {
+		if s := &t.scopes[len(t.scopes)-1]; !s.overflowed && t.pos+bits+length*8 > s.end {
+			msg = "length extends beyond " + s.name
+			s.overflowed = true
+		}
+	}
+	t.annotate(indents, key, length, bits, msg)
+	return length
+}
+
+// begin starts a structure of the given length in bytes, bounded by the named
+// length field. Subsequent fields extending beyond it are reported as errors.
+// The structure is truncated at the end of the enclosing structure, but not
+// at the end of the signal.
+func (t *table) begin(name string, length int) {
+	if !t.annotated {
+		return
+	}
+	end := t.pos + length*8
+	if len(t.scopes) > 1 {
+		end = min(end, t.scopes[len(t.scopes)-1].end)
+	}
+	t.scopes = append(t.scopes, tableScope{name: name, end: end})
+}
+
+// end ends the structure started by the last call to begin, reporting any
+// bytes not described by its fields as not decoded and continuing from its
+// end.
+func (t *table) end(indents int) {
+	if !t.annotated || len(t.scopes) == 0 {
+		return
+	}
+	s := t.scopes[len(t.scopes)-1]
+	if end := min(s.end, len(t.raw)*8); t.pos < end {
+		n := end - t.pos
+		unit := "bytes"
+		if n <= 8 {
+			unit = "byte"
+		}
+		t.annotate(indents, "(unused)", fmt.Sprintf("%d %s", (n+7)/8, unit), n, "not decoded")
+	}
+	t.scopes = t.scopes[:len(t.scopes)-1]
+	t.pos = s.end
+}
+
+// seek moves to the given bit offset, for fields described out of order.
+func (t *table) seek(pos int) {
+	t.pos = pos
+}
+
+// peek returns the value of the next field of the given size in bits, or
+// false if it extends beyond the end of the signal.
+func (t *table) peek(bits int) (uint64, bool) {
+	if bits > 64 || t.pos+bits > len(t.raw)*8 {
+		return 0, false
+	}
+	r := iobit.NewReader(t.raw)
+	r.Skip(uint(t.pos))
+	return r.Uint64(uint(bits)), true
+}
+
+// annotate writes a new row describing the next field, of the given size in
+// bits, with its offset and raw bytes. A field extending beyond an enclosing
+// structure is reported instead of msg.
+func (t *table) annotate(indents int, key string, value any, bits int, msg string) {
+	start, end := t.pos, t.pos+bits
+	t.pos = end
+
+	// report only the first field extending beyond each structure
+	for i := len(t.scopes) - 1; i >= 0; i-- {
+		if s := &t.scopes[i]; end > s.end {
+			if !s.overflowed {
+				msg = "extends beyond " + s.name
+			}
+			for j := i; j < len(t.scopes); j++ {
+				t.scopes[j].overflowed = true
+			}
+			break
+		}
+	}
+
+	offset := fmt.Sprintf("%04x", start/8)
+	if start%8 != 0 {
+		offset += "." + strconv.Itoa(start%8)
+	}
+	hexBytes := "--"
+	if first, last := start/8, min((end+7)/8, len(t.raw)); first < last {
+		if last-first > annotationBytes {
+			hexBytes = fmt.Sprintf("%x..", t.raw[first:first+annotationBytes-1])
+		} else {
+			hexBytes = fmt.Sprintf("%x", t.raw[first:last])
+		}
+	}
+
+	offset = fmt.Sprintf("%-6s  ", offset)
+	hexBytes = fmt.Sprintf("%-*s", annotationWidth-len(offset), hexBytes)
+	if t.color {
+		hexColor := ansiCyan
+		if msg != "" {
+			hexColor = ansiRed
+		}
+		offset = ansiDim + offset + ansiReset
+		hexBytes = hexColor + hexBytes + ansiReset
+	}
 	_, _ = t.b.WriteString(t.prefix)
+	_, _ = t.b.WriteString(offset)
+	_, _ = t.b.WriteString(hexBytes)
+	t.writeRow("", indents, key, value, msg)
+}
+
+// writeRow writes a row with the given prefix, followed by any error message.
+func (t *table) writeRow(prefix string, indents int, key string, value any, msg string) {
+	_, _ = t.b.WriteString(prefix)
 	for range indents {
 		_, _ = t.b.WriteString(t.indent)
 	}
@@ -34,6 +246,13 @@ func (t *table) row(indents int, key string, value any) {
 		_, _ = t.b.WriteString(": ")
 		_, _ = t.b.WriteString(valueString(value))
 	}
+	if msg != "" {
+		msg = "  <- error: " + msg
+		if t.color {
+			msg = ansiRed + msg + ansiReset
+		}
+		_, _ = t.b.WriteString(msg)
+	}
 	_, _ = t.b.WriteRune('\n')
 }
 
@@ -69,6 +288,11 @@ func valueString(value any) string {
 		return strconv.FormatUint(uint64(vt), 10)
 	case uint64:
 		return strconv.FormatUint(vt, 10)
+	case *uint32:
+		if vt == nil {
+			return ""
+		}
+		return strconv.FormatUint(uint64(*vt), 10)
 	case *uint64:
 		if vt == nil {
 			return ""
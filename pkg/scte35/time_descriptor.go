@@ -42,6 +42,12 @@ type TimeDescriptor struct {
 	UTCOffset  uint32   `xml:"utcOffset,attr" json:"utcOffset"`
 }
 
+// Clone returns a deep copy of this TimeDescriptor.
+func (sd *TimeDescriptor) Clone() SpliceDescriptor {
+	c := *sd
+	return &c
+}
+
 // Tag returns the splice_descriptor_tag.
 func (sd *TimeDescriptor) Tag() uint32 {
 	// ensure JSONType is set
@@ -52,12 +58,13 @@ func (sd *TimeDescriptor) Tag() uint32 {
 // table returns the tabular description of this TimeDescriptor.
 func (sd *TimeDescriptor) writeTo(t *table) {
 	t.row(0, "time_descriptor() {", nil)
-	t.row(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", TimeDescriptorTag))
-	t.row(1, "descriptor_length", sd.length())
-	t.row(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII))
-	t.row(1, "tai_seconds", sd.TAISeconds)
-	t.row(1, "tai_ns", sd.TAINS)
-	t.row(1, "utc_offset", sd.UTCOffset)
+	t.field(1, "splice_descriptor_tag", fmt.Sprintf("%#02x", TimeDescriptorTag), 8)
+	t.begin("descriptor_length", t.length(1, "descriptor_length", sd.length(), 8))
+	t.field(1, "identifier", fmt.Sprintf("%#08x, (%s)", CUEIdentifier, CUEIASCII), 32)
+	t.field(1, "tai_seconds", sd.TAISeconds, 48)
+	t.field(1, "tai_ns", sd.TAINS, 32)
+	t.field(1, "utc_offset", sd.UTCOffset, 16)
+	t.end(1)
 	t.row(0, "}", nil)
 }
 
@@ -51,6 +51,13 @@ type TimeSignal struct {
 	SpliceTime SpliceTime `xml:"http://www.scte.org/schemas/35 SpliceTime" json:"spliceTime"`
 }
 
+// Clone returns a deep copy of this TimeSignal.
+func (cmd *TimeSignal) Clone() SpliceCommand {
+	c := *cmd
+	c.SpliceTime = cmd.SpliceTime.clone()
+	return &c
+}
+
 // Type returns the splice_command_type.
 func (cmd *TimeSignal) Type() uint32 {
 	// ensure JSONType is set
@@ -109,9 +116,12 @@ func (cmd *TimeSignal) length() int {
 // writeTo the given table.
 func (cmd *TimeSignal) writeTo(t *table) {
 	t.row(0, "time_signal() {", nil)
-	t.row(1, "time_specified_flag", cmd.timeSpecifiedFlag())
+	t.field(1, "time_specified_flag", cmd.timeSpecifiedFlag(), 1)
 	if cmd.timeSpecifiedFlag() {
-		t.row(1, "pts_time", cmd.SpliceTime.PTSTime)
+		t.reserved(1, 6)
+		t.field(1, "pts_time", cmd.SpliceTime.PTSTime, 33)
+	} else {
+		t.reserved(1, 7)
 	}
 	t.row(0, "}", nil)
 }
@@ -0,0 +1,151 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/bamiaux/iobit"
+)
+
+const (
+	// UMIDBasicLength is the length of a basic UMID, in bytes.
+	UMIDBasicLength = 32
+	// UMIDExtendedLength is the length of an extended UMID, in bytes.
+	UMIDExtendedLength = 64
+)
+
+// umidLabelPrefix is the common prefix of all SMPTE universal labels.
+var umidLabelPrefix = []byte{0x06, 0x0A, 0x2B, 0x34}
+
+// DecodeUMID decodes a binary SMPTE 330M UMID.
+func DecodeUMID(b []byte) (*UMID, error) {
+	if len(b) != UMIDBasicLength && len(b) != UMIDExtendedLength {
+		return nil, fmt.Errorf("umid: %w: expected %d or %d bytes, got %d", ErrInvalidUPID, UMIDBasicLength, UMIDExtendedLength, len(b))
+	}
+	if !bytes.HasPrefix(b, umidLabelPrefix) {
+		return nil, fmt.Errorf("umid: %w: universal label %X is not a SMPTE label", ErrInvalidUPID, b[:12])
+	}
+
+	u := &UMID{}
+	r := iobit.NewReader(b)
+	copy(u.UniversalLabel[:], r.Bytes(len(u.UniversalLabel)))
+	u.Length = r.Uint32(8)
+	u.InstanceNumber = r.Uint32(24)
+	copy(u.MaterialNumber[:], r.Bytes(len(u.MaterialNumber)))
+	if len(b) == UMIDExtendedLength {
+		u.SourcePack = &UMIDSourcePack{}
+		copy(u.SourcePack.TimeDate[:], r.Bytes(len(u.SourcePack.TimeDate)))
+		copy(u.SourcePack.SpatialCoordinates[:], r.Bytes(len(u.SourcePack.SpatialCoordinates)))
+		copy(u.SourcePack.Country[:], r.Bytes(len(u.SourcePack.Country)))
+		copy(u.SourcePack.Organization[:], r.Bytes(len(u.SourcePack.Organization)))
+		copy(u.SourcePack.User[:], r.Bytes(len(u.SourcePack.User)))
+	}
+
+	if int(u.Length) != len(b)-13 {
+		return u, fmt.Errorf("umid: %w: length %#02x does not match %d byte UMID", ErrInvalidUPID, u.Length, len(b))
+	}
+	return u, nil
+}
+
+// ParseUMID parses a UMID from its textual representation; hexadecimal
+// groups of 4 bytes separated by a dot (".").
+func ParseUMID(s string) (*UMID, error) {
+	b, err := hex.DecodeString(strings.ReplaceAll(s, ".", ""))
+	if err != nil {
+		return nil, fmt.Errorf("umid: %w: %s", ErrInvalidUPID, err)
+	}
+	return DecodeUMID(b)
+}
+
+// UMID is a structured SMPTE 330M Unique Material Identifier
+// (segmentation_upid_type 0x04).
+type UMID struct {
+	UniversalLabel [12]byte
+	Length         uint32
+	InstanceNumber uint32
+	MaterialNumber [16]byte
+	// SourcePack is only present in extended UMIDs.
+	SourcePack *UMIDSourcePack
+}
+
+// Bytes returns the binary representation of this UMID.
+func (u *UMID) Bytes() []byte {
+	length := UMIDBasicLength
+	if u.SourcePack != nil {
+		length = UMIDExtendedLength
+	}
+
+	buf := make([]byte, length)
+	iow := iobit.NewWriter(buf)
+	_, _ = iow.Write(u.UniversalLabel[:])
+	iow.PutUint32(8, u.Length)
+	iow.PutUint32(24, u.InstanceNumber)
+	_, _ = iow.Write(u.MaterialNumber[:])
+	if u.SourcePack != nil {
+		_, _ = iow.Write(u.SourcePack.TimeDate[:])
+		_, _ = iow.Write(u.SourcePack.SpatialCoordinates[:])
+		_, _ = iow.Write(u.SourcePack.Country[:])
+		_, _ = iow.Write(u.SourcePack.Organization[:])
+		_, _ = iow.Write(u.SourcePack.User[:])
+	}
+	_ = iow.Flush()
+	return buf
+}
+
+// SegmentationUPID returns this UMID as a SegmentationUPID.
+func (u *UMID) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeUMID,
+		Format: SegmentationUPIDFormatText,
+		Value:  u.String(),
+	}
+}
+
+// String returns the textual representation of this UMID.
+func (u *UMID) String() string {
+	return formatUMID(u.Bytes())
+}
+
+// UMIDSourcePack contains the signature metadata appended to an extended UMID.
+type UMIDSourcePack struct {
+	TimeDate           [8]byte
+	SpatialCoordinates [12]byte
+	Country            [4]byte
+	Organization       [4]byte
+	User               [4]byte
+}
+
+// UMID returns the Value of a UMID SegmentationUPID as a UMID.
+func (upid *SegmentationUPID) UMID() (*UMID, error) {
+	if upid.Type != SegmentationUPIDTypeUMID {
+		return nil, fmt.Errorf("umid: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseUMID(upid.Value)
+}
+
+// formatUMID returns the textual representation of a binary UMID.
+func formatUMID(b []byte) string {
+	parts := make([]string, 0, (len(b)+3)/4)
+	for i := 0; i < len(b); i += 4 {
+		parts = append(parts, fmt.Sprintf("%X", b[i:min(i+4, len(b))]))
+	}
+	return strings.Join(parts, ".")
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ParseURI parses and validates an absolute RFC 3986 URI (ie,
+// urn:uuid:f81d4fae-7dec-11d0-a765-00a0c91e6bf6). Characters outside of the
+// RFC 3986 character set must be percent-encoded; see EncodeURI.
+func ParseURI(s string) (*url.URL, error) {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '%':
+			if i+2 >= len(s) || !isHex(s[i+1]) || !isHex(s[i+2]) {
+				return nil, fmt.Errorf("uri: %w: invalid percent-encoding at offset %d", ErrInvalidUPID, i)
+			}
+			i += 2
+		case isURIUnreserved(c), strings.IndexByte(uriReserved, c) >= 0:
+		default:
+			return nil, fmt.Errorf("uri: %w: invalid character %q at offset %d", ErrInvalidUPID, c, i)
+		}
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("uri: %w: %s", ErrInvalidUPID, err)
+	}
+	if u.Scheme == "" {
+		return nil, fmt.Errorf("uri: %w: %q is not an absolute URI", ErrInvalidUPID, s)
+	}
+	return u, nil
+}
+
+// EncodeURI percent-encodes any characters in s that are not permitted in an
+// RFC 3986 URI, such as non-ASCII characters or a '%' that does not start a
+// percent-encoding. Like other text UPIDs, characters are encoded as Latin-1
+// bytes, or as UTF-8 if s contains characters outside of Latin-1.
+func EncodeURI(s string) string {
+	b, err := charmap.ISO8859_1.NewEncoder().Bytes([]byte(s))
+	if err != nil {
+		b = []byte(s)
+	}
+	return encodeURI(b)
+}
+
+// encodeURI returns b as text, percent-encoding any bytes that are not
+// permitted in an RFC 3986 URI. Existing percent-encodings are preserved.
+func encodeURI(b []byte) string {
+	var sb strings.Builder
+	for i, c := range b {
+		switch {
+		case c == '%':
+			if i+2 < len(b) && isHex(b[i+1]) && isHex(b[i+2]) {
+				_ = sb.WriteByte(c)
+				continue
+			}
+		case isURIUnreserved(c), strings.IndexByte(uriReserved, c) >= 0:
+			_ = sb.WriteByte(c)
+			continue
+		}
+		_, _ = fmt.Fprintf(&sb, "%%%02X", c)
+	}
+	return sb.String()
+}
+
+// URI returns the Value of a URI SegmentationUPID as a URL.
+func (upid *SegmentationUPID) URI() (*url.URL, error) {
+	if upid.Type != SegmentationUPIDTypeURI {
+		return nil, fmt.Errorf("uri: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	return ParseURI(upid.Value)
+}
+
+// uriReserved contains the RFC 3986 reserved characters.
+const uriReserved = ":/?#[]@!$&'()*+,;="
+
+// isURIUnreserved returns true if c is an RFC 3986 unreserved character.
+func isURIUnreserved(c byte) bool {
+	return (c >= 'a' && c <= 'z') ||
+		(c >= 'A' && c <= 'Z') ||
+		(c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// isHex returns true if c is a hexadecimal digit.
+func isHex(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
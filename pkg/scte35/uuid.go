@@ -0,0 +1,91 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// UUIDLength is the length of a UUID, in bytes.
+const UUIDLength = 16
+
+// DecodeUUID decodes a binary RFC 4122 UUID.
+func DecodeUUID(b []byte) (UUID, error) {
+	var u UUID
+	if len(b) != UUIDLength {
+		return u, fmt.Errorf("uuid: %w: expected %d bytes, got %d", ErrInvalidUPID, UUIDLength, len(b))
+	}
+	copy(u[:], b)
+	return u, nil
+}
+
+// ParseUUID parses a UUID from its RFC 4122 textual representation (ie,
+// f81d4fae-7dec-11d0-a765-00a0c91e6bf6), with or without the "urn:uuid:"
+// prefix.
+func ParseUUID(s string) (UUID, error) {
+	var u UUID
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "urn:uuid:")
+	if len(s) != 36 || s[8] != '-' || s[13] != '-' || s[18] != '-' || s[23] != '-' {
+		return u, fmt.Errorf("uuid: %w: unrecognized format %q", ErrInvalidUPID, s)
+	}
+	b, err := hex.DecodeString(strings.ReplaceAll(s, "-", ""))
+	if err != nil {
+		return u, fmt.Errorf("uuid: %w: %s", ErrInvalidUPID, err)
+	}
+	return DecodeUUID(b)
+}
+
+// UUID is a structured RFC 4122 Universally Unique Identifier
+// (segmentation_upid_type 0x10).
+type UUID [UUIDLength]byte
+
+// Bytes returns the binary representation of this UUID.
+func (u UUID) Bytes() []byte {
+	return u[:]
+}
+
+// SegmentationUPID returns this UUID as a SegmentationUPID.
+func (u UUID) SegmentationUPID() SegmentationUPID {
+	return SegmentationUPID{
+		Type:   SegmentationUPIDTypeUUID,
+		Format: SegmentationUPIDFormatText,
+		Value:  u.String(),
+	}
+}
+
+// String returns the RFC 4122 textual representation of this UUID.
+func (u UUID) String() string {
+	h := hex.EncodeToString(u[:])
+	return h[0:8] + "-" + h[8:12] + "-" + h[12:16] + "-" + h[16:20] + "-" + h[20:32]
+}
+
+// UUID returns the Value of a UUID SegmentationUPID as a UUID.
+func (upid *SegmentationUPID) UUID() (UUID, error) {
+	if upid.Type != SegmentationUPIDTypeUUID {
+		return UUID{}, fmt.Errorf("uuid: %w: segmentation_upid_type is %#02x", ErrInvalidUPID, upid.Type)
+	}
+	if upid.Format == SegmentationUPIDFormatBase64 {
+		b, err := upid.base64Bytes()
+		if err != nil {
+			return UUID{}, fmt.Errorf("uuid: %w", err)
+		}
+		return DecodeUUID(b)
+	}
+	return ParseUUID(upid.Value)
+}
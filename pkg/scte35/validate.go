@@ -0,0 +1,381 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+const (
+	// RuleDecode identifies findings that prevent a signal from being decoded.
+	// It is always enabled.
+	RuleDecode = "decode"
+	// RuleCRC verifies the CRC_32.
+	RuleCRC = "crc"
+	// RuleReservedBits verifies that reserved bits are set to 1 and fixed
+	// value fields are set correctly.
+	RuleReservedBits = "reserved-bits"
+	// RuleSegmentation verifies that the segment_num, segments_expected and
+	// sub_segment fields of each segmentation_descriptor are consistent with
+	// its segmentation_type_id, that Content Identification carries a
+	// segmentation_upid, and that each segmentation_upid is well formed.
+	RuleSegmentation = "segmentation"
+	// RuleDuration verifies that break_duration and segmentation_duration are
+	// present where required, and absent where not used.
+	RuleDuration = "duration"
+	// RuleDescriptorPlacement verifies that each splice_descriptor is carried
+	// with a splice_command it applies to.
+	RuleDescriptorPlacement = "descriptor-placement"
+)
+
+// ValidationRules returns the names of the rules that can be passed to
+// Validate.
+func ValidationRules() []string {
+	return []string{
+		RuleCRC,
+		RuleReservedBits,
+		RuleSegmentation,
+		RuleDuration,
+		RuleDescriptorPlacement,
+	}
+}
+
+// ParseValidationRules resolves a list of rule names to the rules to run. Rules
+// prefixed with '-' are disabled; if no rules are enabled by name, all
+// ValidationRules other than those disabled are run. An error is returned for
+// unknown rules or if no rules remain.
+func ParseValidationRules(spec []string) ([]string, error) {
+	all := ValidationRules()
+	var enabled, disabled []string
+	for _, r := range spec {
+		name := strings.TrimPrefix(r, "-")
+		if !slices.Contains(all, name) {
+			return nil, fmt.Errorf("unknown rule: %q (expected one of %s)", name, strings.Join(all, ", "))
+		}
+		if strings.HasPrefix(r, "-") {
+			disabled = append(disabled, name)
+		} else {
+			enabled = append(enabled, name)
+		}
+	}
+	if len(enabled) == 0 {
+		enabled = all
+	}
+	enabled = slices.DeleteFunc(enabled, func(r string) bool {
+		return slices.Contains(disabled, r)
+	})
+	if len(enabled) == 0 {
+		return nil, fmt.Errorf("no rules enabled")
+	}
+	return enabled, nil
+}
+
+// Severity is the severity of a Finding.
+type Severity int
+
+const (
+	// SeverityWarning indicates a signal that may not be handled as intended
+	// by all receivers.
+	SeverityWarning Severity = iota
+	// SeverityError indicates a signal that does not conform to ANSI/SCTE 35.
+	SeverityError
+)
+
+// String returns the name of this Severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText encodes a Severity as its name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Finding is the result of a conformance check that failed.
+type Finding struct {
+	Rule     string   `xml:"rule,attr" json:"rule"`
+	Severity Severity `xml:"severity,attr" json:"severity"`
+	Message  string   `xml:",chardata" json:"message"`
+}
+
+// String returns a human-readable description of this Finding.
+func (f Finding) String() string {
+	return fmt.Sprintf("%s: [%s] %s", f.Severity, f.Rule, f.Message)
+}
+
+// Validate decodes the binary splice_info_section in b and runs the named
+// conformance rules, returning the decoded SpliceInfoSection and any findings.
+// If no rules are provided all ValidationRules are run; unknown rules are
+// ignored.
+//
+// A signal that cannot be decoded returns a single RuleDecode finding, and the
+// remaining rules are not run. An invalid CRC_32 is reported by RuleCRC rather
+// than as a decoding error.
+func Validate(b []byte, rules ...string) (*SpliceInfoSection, []Finding) {
+	if len(rules) == 0 {
+		rules = ValidationRules()
+	}
+	enabled := map[string]bool{}
+	for _, r := range rules {
+		enabled[r] = true
+	}
+
+	var findings []Finding
+	sis := &SpliceInfoSection{}
+	if err := sis.Decode(b); err != nil && !errors.Is(err, ErrInvalidUPID) {
+		if !errors.Is(err, ErrCRC32Invalid) {
+			return sis, []Finding{{Rule: RuleDecode, Severity: SeverityError, Message: err.Error()}}
+		}
+		if enabled[RuleCRC] {
+			findings = append(findings, Finding{Rule: RuleCRC, Severity: SeverityError, Message: err.Error()})
+		}
+	}
+	if sis.legacy {
+		findings = append(findings, Finding{Rule: RuleDecode, Severity: SeverityWarning, Message: "legacy splice_command_length 0xfff"})
+	}
+
+	if enabled[RuleReservedBits] {
+		findings = append(findings, validateReservedBits(sis, b)...)
+	}
+	if enabled[RuleSegmentation] {
+		findings = append(findings, validateSegmentation(sis)...)
+	}
+	if enabled[RuleDuration] {
+		findings = append(findings, validateDuration(sis)...)
+	}
+	if enabled[RuleDescriptorPlacement] {
+		findings = append(findings, validateDescriptorPlacement(sis)...)
+	}
+	return sis, findings
+}
+
+// validateReservedBits compares b to the re-encoded SpliceInfoSection. Decode
+// ignores reserved bits and Encode sets them to 1, so bits that are cleared in
+// b but set when re-encoded are reserved bits that are not set.
+func validateReservedBits(sis *SpliceInfoSection, b []byte) []Finding {
+	finding := func(severity Severity, format string, a ...interface{}) Finding {
+		return Finding{Rule: RuleReservedBits, Severity: severity, Message: fmt.Sprintf(format, a...)}
+	}
+
+	if sis.EncryptedPacketFlag() {
+		return []Finding{finding(SeverityWarning, "reserved bits not checked in encrypted_packet")}
+	}
+	enc, err := sis.Encode()
+	if err != nil || len(enc) != len(b) {
+		return []Finding{finding(SeverityWarning, "reserved bits not checked: signal does not re-encode to %d bytes", len(b))}
+	}
+	if sis.legacy {
+		// splice_command_length
+		enc[11] |= 0x0F
+		enc[12] = 0xFF
+	}
+
+	var findings []Finding
+	if b[0] != TableID {
+		findings = append(findings, finding(SeverityError, "table_id %#02x, expected %#02x", b[0], TableID))
+	}
+	// skip the CRC_32, which is verified by RuleCRC
+	for i := 1; i < len(b)-4; i++ {
+		switch {
+		case b[i] == enc[i]:
+		case b[i]|enc[i] == enc[i]:
+			findings = append(findings, finding(SeverityError, "reserved bits not set to 1 at byte %d: %#02x, expected %#02x", i, b[i], enc[i]))
+		default:
+			findings = append(findings, finding(SeverityWarning, "byte %d does not round trip: %#02x, re-encoded as %#02x", i, b[i], enc[i]))
+		}
+	}
+	return findings
+}
+
+// validateSegmentation checks each segmentation_descriptor.
+func validateSegmentation(sis *SpliceInfoSection) []Finding {
+	var findings []Finding
+	for i, d := range sis.SpliceDescriptors {
+		sd, ok := d.(*SegmentationDescriptor)
+		if !ok {
+			continue
+		}
+		finding := func(severity Severity, format string, a ...interface{}) Finding {
+			msg := fmt.Sprintf("segmentation_descriptor[%d]: ", i) + fmt.Sprintf(format, a...)
+			return Finding{Rule: RuleSegmentation, Severity: severity, Message: msg}
+		}
+
+		if !sd.SegmentationEventCancelIndicator && sd.Name() == "Unknown" {
+			findings = append(findings, finding(SeverityWarning, "unknown segmentation_type_id %#02x", sd.SegmentationTypeID))
+		}
+		for _, err := range validateSegmentationFields(sd) {
+			findings = append(findings, finding(SeverityError, "%s", strings.TrimPrefix(err.Error(), ErrInvalidSignal.Error()+": ")))
+		}
+		for _, upid := range sd.SegmentationUPIDs {
+			switch upid.Type {
+			case SegmentationUPIDTypeUserDefined,
+				SegmentationUPIDTypeISCI,
+				SegmentationUPIDTypeISANDeprecated:
+				findings = append(findings, finding(SeverityWarning, "segmentation_upid_type %#02x (%s) is deprecated", upid.Type, upid.Name()))
+			}
+			if err := upid.Validate(); err != nil {
+				severity := SeverityError
+				if !upidFormatMandated(upid.Type) {
+					severity = SeverityWarning
+				}
+				findings = append(findings, finding(severity, "%s", err))
+			}
+		}
+	}
+	return findings
+}
+
+// upidFormatMandated returns true if the format of a segmentation_upid_type is
+// mandated. User Defined, ADS and SCR values are informational, so a value that
+// does not match the format expected by this package is only a warning.
+func upidFormatMandated(upidType uint32) bool {
+	switch upidType {
+	case SegmentationUPIDTypeUserDefined,
+		SegmentationUPIDTypeADS,
+		SegmentationUPIDTypeSCR:
+		return false
+	default:
+		return true
+	}
+}
+
+// validateDuration checks the break_duration and segmentation_durations.
+func validateDuration(sis *SpliceInfoSection) []Finding {
+	var findings []Finding
+	finding := func(severity Severity, format string, a ...interface{}) {
+		findings = append(findings, Finding{Rule: RuleDuration, Severity: severity, Message: fmt.Sprintf(format, a...)})
+	}
+
+	if si, ok := sis.SpliceCommand.(*SpliceInsert); ok && !si.SpliceEventCancelIndicator {
+		switch {
+		case si.BreakDuration == nil && si.OutOfNetworkIndicator:
+			finding(SeverityWarning, "splice_insert: break_duration is recommended when out_of_network_indicator is 1")
+		case si.BreakDuration != nil && !si.OutOfNetworkIndicator:
+			finding(SeverityWarning, "splice_insert: break_duration is not used when out_of_network_indicator is 0")
+		case si.BreakDuration != nil && si.BreakDuration.Duration == 0:
+			finding(SeverityWarning, "splice_insert: break_duration is 0")
+		}
+	}
+
+	for i, d := range sis.SpliceDescriptors {
+		sd, ok := d.(*SegmentationDescriptor)
+		if !ok || sd.SegmentationEventCancelIndicator {
+			continue
+		}
+		switch {
+		case sd.SegmentationDuration == nil && segmentationDurationRequired(sd.SegmentationTypeID):
+			finding(SeverityError, "segmentation_descriptor[%d]: segmentation_duration is required for %s", i, sd.Name())
+		case sd.SegmentationDuration == nil && segmentationDurationRecommended(sd.SegmentationTypeID):
+			finding(SeverityWarning, "segmentation_descriptor[%d]: segmentation_duration is recommended for %s", i, sd.Name())
+		case sd.SegmentationDuration != nil && segmentationEnd(sd.SegmentationTypeID):
+			finding(SeverityWarning, "segmentation_descriptor[%d]: segmentation_duration is not used for %s", i, sd.Name())
+		case sd.SegmentationDuration != nil && *sd.SegmentationDuration == 0:
+			finding(SeverityWarning, "segmentation_descriptor[%d]: segmentation_duration is 0", i)
+		}
+	}
+	return findings
+}
+
+// validateDescriptorPlacement checks that each splice_descriptor is carried
+// with an applicable splice_command.
+func validateDescriptorPlacement(sis *SpliceInfoSection) []Finding {
+	var findings []Finding
+	finding := func(format string, a ...interface{}) {
+		findings = append(findings, Finding{Rule: RuleDescriptorPlacement, Severity: SeverityWarning, Message: fmt.Sprintf(format, a...)})
+	}
+
+	var commandType uint32
+	if sis.SpliceCommand != nil {
+		commandType = sis.SpliceCommand.Type()
+	}
+	if commandType == TimeSignalType && len(sis.SpliceDescriptors) == 0 {
+		finding("time_signal does not carry any splice_descriptors")
+	}
+
+	for i, sd := range sis.SpliceDescriptors {
+		switch sd.(type) {
+		case *AvailDescriptor:
+			if commandType != SpliceInsertType {
+				finding("avail_descriptor[%d]: only applies to splice_insert", i)
+			}
+		case *DTMFDescriptor:
+			if commandType != SpliceInsertType {
+				finding("dtmf_descriptor[%d]: only applies to splice_insert", i)
+			}
+		case *TimeDescriptor:
+			if commandType != TimeSignalType {
+				finding("time_descriptor[%d]: only applies to time_signal", i)
+			}
+		case *SegmentationDescriptor:
+			if commandType != TimeSignalType && commandType != SpliceInsertType {
+				finding("segmentation_descriptor[%d]: only applies to time_signal or splice_insert", i)
+			}
+		}
+	}
+	return findings
+}
+
+// segmentationDurationRequired returns true if a Start segmentation_type_id
+// requires a segmentation_duration.
+func segmentationDurationRequired(segmentationTypeID uint32) bool {
+	switch segmentationTypeID {
+	case SegmentationTypeProviderPOStart,
+		SegmentationTypeDistributorPOStart,
+		SegmentationTypeProviderOverlayPOStart,
+		SegmentationTypeDistributorOverlayPOStart:
+		return true
+	default:
+		return false
+	}
+}
+
+// segmentationDurationRecommended returns true if a Start segmentation_type_id
+// should include a segmentation_duration.
+func segmentationDurationRecommended(segmentationTypeID uint32) bool {
+	switch segmentationTypeID {
+	case SegmentationTypeBreakStart,
+		SegmentationTypeProviderAdStart,
+		SegmentationTypeDistributorAdStart,
+		SegmentationTypeProviderAdBlockStart,
+		SegmentationTypeDistributorAdBlockStart:
+		return true
+	default:
+		return false
+	}
+}
+
+// segmentationEnd returns true if segmentationTypeID is an End type.
+func segmentationEnd(segmentationTypeID uint32) bool {
+	switch {
+	case segmentationTypeID == SegmentationTypeProgramEnd:
+		return true
+	case segmentationTypeID >= SegmentationTypeChapterStart && segmentationTypeID <= SegmentationTypeNetworkEnd:
+		return segmentationTypeID%2 == 1
+	default:
+		return false
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	// Sample 14.3 time_signal - Placement Opportunity End
+	const valid = "/DAvAAAAAAAA///wBQb+dGKQoAAZAhdDVUVJSAAAjn+fCAgAAAAALKChijUCAKnMZ1g="
+
+	cases := map[string]struct {
+		signal   string
+		mutate   func(b []byte) []byte
+		rules    []string
+		expected []string
+	}{
+		"Valid": {
+			signal: valid,
+		},
+		"CRC": {
+			signal: valid,
+			mutate: func(b []byte) []byte {
+				b[len(b)-1]++
+				return b
+			},
+			expected: []string{"error: [crc] splice_info_section: CRC_32 not valid"},
+		},
+		"Reserved Bits": {
+			signal: valid,
+			mutate: func(b []byte) []byte {
+				b[14] &^= 0x7E // time_signal splice_time() reserved
+				return b
+			},
+			rules:    []string{scte35.RuleReservedBits},
+			expected: []string{"error: [reserved-bits] reserved bits not set to 1 at byte 14: 0x80, expected 0xfe"},
+		},
+		"Table ID": {
+			signal: valid,
+			mutate: func(b []byte) []byte {
+				b[0] = 0xFD
+				return b
+			},
+			rules:    []string{scte35.RuleReservedBits},
+			expected: []string{"error: [reserved-bits] table_id 0xfd, expected 0xfc"},
+		},
+		"Decode Error": {
+			signal: valid,
+			mutate: func(b []byte) []byte {
+				return b[:20]
+			},
+			expected: []string{"error: [decode] splice_info_section: buffer overflow"},
+		},
+		"Legacy Splice Command Length": {
+			signal:   "/DAeAAAAAAAAAP///wViAA/nf18ACQAAAAAskJv+YPtE",
+			expected: []string{"warning: [decode] legacy splice_command_length 0xfff"},
+		},
+		"Segmentation": {
+			signal: "/DBPAAAAAAAAAP/wBQb/Gq9LggA5AAVTQVBTCwIwQ1VFSf////9//wAAFI4PDxx1cm46bmJjdW5pLmNvbTpicmM6NDk5ODY2NDM0MQoBbM98zw==",
+			rules:  []string{scte35.RuleSegmentation},
+			expected: []string{
+				"error: [segmentation] segmentation_descriptor[1]: segment_num 10 exceeds segments_expected 1",
+			},
+		},
+		"Deprecated UPID": {
+			signal: "/DA4AAAAAAAAAP/wFAUABDEAf+//mWEhzP4Azf5gAQAAAAATAhFDVUVJAAAAAX+/AQIwNAEAAKeYO3Q=",
+			rules:  []string{scte35.RuleSegmentation},
+			expected: []string{
+				"warning: [segmentation] segmentation_descriptor[0]: segmentation_upid_type 0x01 (User Defined) is deprecated",
+			},
+		},
+		"Sub Segment Num Exceeds Sub Segments Expected": {
+			signal: segmentationSignal(&scte35.SegmentationDescriptor{
+				SegmentationTypeID:  scte35.SegmentationTypeDistributorPOStart,
+				SubSegmentNum:       ptr(uint32(3)),
+				SubSegmentsExpected: ptr(uint32(2)),
+			}),
+			rules: []string{scte35.RuleSegmentation},
+			expected: []string{
+				"error: [segmentation] segmentation_descriptor[0]: sub_segment_num 3 exceeds sub_segments_expected 2",
+			},
+		},
+		"Missing Content Identification UPID": {
+			signal: segmentationSignal(&scte35.SegmentationDescriptor{
+				SegmentationTypeID: scte35.SegmentationTypeContentIdentification,
+			}),
+			rules: []string{scte35.RuleSegmentation},
+			expected: []string{
+				"error: [segmentation] segmentation_descriptor[0]: segmentation_upid is required for Content Identification",
+			},
+		},
+		"Invalid UPID": {
+			signal: segmentationSignal(&scte35.SegmentationDescriptor{
+				SegmentationTypeID: scte35.SegmentationTypeProviderAdStart,
+				SegmentationUPIDs: []scte35.SegmentationUPID{
+					{Type: scte35.SegmentationUPIDTypeAdID, Format: scte35.SegmentationUPIDFormatText, Value: "ABC"},
+				},
+			}),
+			rules: []string{scte35.RuleSegmentation},
+			expected: []string{
+				"error: [segmentation] segmentation_descriptor[0]: ad-id: invalid segmentation_upid: expected 12 characters, got 3",
+			},
+		},
+		"Invalid Informational UPID": {
+			signal: segmentationSignal(&scte35.SegmentationDescriptor{
+				SegmentationTypeID: scte35.SegmentationTypeProviderAdStart,
+				SegmentationUPIDs: []scte35.SegmentationUPID{
+					{Type: scte35.SegmentationUPIDTypeADS, Format: scte35.SegmentationUPIDFormatText, Value: "="},
+				},
+			}),
+			rules: []string{scte35.RuleSegmentation},
+			expected: []string{
+				"warning: [segmentation] segmentation_descriptor[0]: ads: invalid segmentation_upid: malformed key-value pair \"=\"",
+			},
+		},
+		"Duration": {
+			signal: (&scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.SegmentationDescriptor{SegmentationTypeID: scte35.SegmentationTypeProviderPOStart},
+					&scte35.SegmentationDescriptor{SegmentationTypeID: scte35.SegmentationTypeBreakStart},
+					&scte35.SegmentationDescriptor{SegmentationTypeID: scte35.SegmentationTypeBreakEnd, SegmentationDuration: ptr(uint64(100))},
+				},
+				Tier:    0xFFF,
+				SAPType: scte35.SAPTypeNotSpecified,
+			}).Base64(),
+			rules: []string{scte35.RuleDuration},
+			expected: []string{
+				"error: [duration] segmentation_descriptor[0]: segmentation_duration is required for Provider Placement Opportunity Start",
+				"warning: [duration] segmentation_descriptor[1]: segmentation_duration is recommended for Break Start",
+				"warning: [duration] segmentation_descriptor[2]: segmentation_duration is not used for Break End",
+			},
+		},
+		"Break Duration": {
+			signal:   "/DAqAAAAAAAAAP/wDwUAAHn+f8/+QubGOQAAAAAACgAIQ1VFSQAAAADizteX",
+			expected: []string{"warning: [duration] splice_insert: break_duration is recommended when out_of_network_indicator is 1"},
+		},
+		"Descriptor Placement": {
+			signal: (&scte35.SpliceInfoSection{
+				SpliceCommand: scte35.NewTimeSignal(0x100),
+				SpliceDescriptors: scte35.SpliceDescriptors{
+					&scte35.AvailDescriptor{ProviderAvailID: 1},
+				},
+				Tier:    0xFFF,
+				SAPType: scte35.SAPTypeNotSpecified,
+			}).Base64(),
+			expected: []string{"warning: [descriptor-placement] avail_descriptor[0]: only applies to splice_insert"},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			b, err := base64.StdEncoding.DecodeString(c.signal)
+			require.NoError(t, err)
+			if c.mutate != nil {
+				b = c.mutate(b)
+			}
+
+			_, findings := scte35.Validate(b, c.rules...)
+			actual := make([]string, len(findings))
+			for i, f := range findings {
+				actual[i] = f.String()
+			}
+			if c.expected == nil {
+				c.expected = []string{}
+			}
+			require.Equal(t, c.expected, actual)
+		})
+	}
+}
+
+func TestParseValidationRules(t *testing.T) {
+	cases := map[string]struct {
+		spec     []string
+		expected []string
+		err      string
+	}{
+		"Default": {
+			expected: scte35.ValidationRules(),
+		},
+		"Enable": {
+			spec:     []string{scte35.RuleDuration, scte35.RuleCRC},
+			expected: []string{scte35.RuleDuration, scte35.RuleCRC},
+		},
+		"Disable": {
+			spec:     []string{"-" + scte35.RuleReservedBits, "-" + scte35.RuleDuration},
+			expected: []string{scte35.RuleCRC, scte35.RuleSegmentation, scte35.RuleDescriptorPlacement},
+		},
+		"Unknown": {
+			spec: []string{"bogus"},
+			err:  `unknown rule: "bogus"`,
+		},
+		"None": {
+			spec: []string{scte35.RuleCRC, "-" + scte35.RuleCRC},
+			err:  "no rules enabled",
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			rules, err := scte35.ParseValidationRules(c.spec)
+			if c.err != "" {
+				require.ErrorContains(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, c.expected, rules)
+		})
+	}
+}
+
+// segmentationSignal returns a base-64 encoded time_signal carrying a single
+// SegmentationDescriptor.
+func segmentationSignal(sd *scte35.SegmentationDescriptor) string {
+	sd.SegmentationEventID = 1
+	return (&scte35.SpliceInfoSection{
+		SpliceCommand:     scte35.NewTimeSignal(0x100),
+		SpliceDescriptors: scte35.SpliceDescriptors{sd},
+		Tier:              0xFFF,
+		SAPType:           scte35.SAPTypeNotSpecified,
+	}).Base64()
+}
@@ -0,0 +1,343 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+// Package scte35http provides an http.Handler exposing the scte35 package over
+// HTTP, along with the signal representations it shares with the scte35
+// command line interface.
+package scte35http
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+)
+
+// DefaultMaxRequestBytes is the default limit on the size of a request body.
+const DefaultMaxRequestBytes = 64 << 10
+
+// Handler is an http.Handler with the following endpoints:
+//
+//	GET  /health                  returns {"status":"ok"}
+//	POST /decode                  decodes the signal in the request body
+//	POST /encode                  encodes the signal as base-64 and hex
+//	POST /validate?rules=         runs scte35.Validate on the signal
+//	POST /convert?to=&from=       converts the signal (see scte35.WriteSignal)
+//
+// Request bodies may use any representation supported by scte35.ReadSignal.
+// The representation is detected unless the from query parameter is set or the
+// Content-Type is application/octet-stream (scte35.RepresentationBinary).
+//
+// Responses are JSON, XML or text according to the first supported media type
+// in the Accept header (application/json, application/xml or text/plain),
+// defaulting to JSON. Errors are returned in the same format with an
+// appropriate status code.
+//
+// Use http.StripPrefix to mount a Handler below the root of a server.
+type Handler struct {
+	// MaxRequestBytes limits the size of request bodies; larger requests are
+	// rejected with 413 Request Entity Too Large. If zero,
+	// DefaultMaxRequestBytes is used.
+	MaxRequestBytes int64
+}
+
+// NewHandler returns a Handler using DefaultMaxRequestBytes.
+func NewHandler() *Handler {
+	return &Handler{MaxRequestBytes: DefaultMaxRequestBytes}
+}
+
+// ServeHTTP implements http.Handler.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	format := negotiate(r.Header.Get("Accept"))
+
+	var method string
+	var serve func(w http.ResponseWriter, r *http.Request, format string) error
+	switch r.URL.Path {
+	case "/health":
+		method, serve = http.MethodGet, h.health
+	case "/decode":
+		method, serve = http.MethodPost, h.decode
+	case "/encode":
+		method, serve = http.MethodPost, h.encode
+	case "/validate":
+		method, serve = http.MethodPost, h.validate
+	case "/convert":
+		method, serve = http.MethodPost, h.convert
+	default:
+		writeError(w, format, http.StatusNotFound, fmt.Errorf("not found: %s", r.URL.Path))
+		return
+	}
+	if r.Method != method && !(method == http.MethodGet && r.Method == http.MethodHead) {
+		w.Header().Set("Allow", method)
+		writeError(w, format, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		return
+	}
+
+	if err := serve(w, r, format); err != nil {
+		var he *httpError
+		if errors.As(err, &he) {
+			writeError(w, format, he.status, he.err)
+			return
+		}
+		writeError(w, format, http.StatusInternalServerError, err)
+	}
+}
+
+// health handles GET /health.
+func (h *Handler) health(w http.ResponseWriter, _ *http.Request, format string) error {
+	res := struct {
+		XMLName xml.Name `xml:"Health" json:"-"`
+		Status  string   `xml:"status,attr" json:"status"`
+	}{Status: "ok"}
+	return writeResponse(w, format, &res, res.Status+"\n")
+}
+
+// decode handles POST /decode.
+func (h *Handler) decode(w http.ResponseWriter, r *http.Request, format string) error {
+	sis, err := h.readSignal(w, r)
+	if err != nil {
+		return err
+	}
+	b, err := scte35.WriteSignal(sis, format)
+	if err != nil {
+		return err
+	}
+	return writeBody(w, format, http.StatusOK, b)
+}
+
+// encodeResponse is the response to POST /encode.
+type encodeResponse struct {
+	XMLName xml.Name `xml:"Encoded" json:"-"`
+	Base64  string   `xml:"Base64" json:"base64"`
+	Hex     string   `xml:"Hex" json:"hex"`
+}
+
+// encode handles POST /encode.
+func (h *Handler) encode(w http.ResponseWriter, r *http.Request, format string) error {
+	sis, err := h.readSignal(w, r)
+	if err != nil {
+		return err
+	}
+	if _, err := sis.Encode(); err != nil {
+		return &httpError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	res := encodeResponse{Base64: sis.Base64(), Hex: sis.Hex()}
+	return writeResponse(w, format, &res, fmt.Sprintf("Base64: %s\nHex   : %s\n", res.Base64, res.Hex))
+}
+
+// validateResponse is the response to POST /validate.
+type validateResponse struct {
+	XMLName  xml.Name         `xml:"Validation" json:"-"`
+	Valid    bool             `xml:"valid,attr" json:"valid"`
+	Findings []scte35.Finding `xml:"Finding" json:"findings"`
+}
+
+// validate handles POST /validate.
+func (h *Handler) validate(w http.ResponseWriter, r *http.Request, format string) error {
+	var spec []string
+	if s := r.URL.Query().Get("rules"); s != "" {
+		spec = strings.Split(s, ",")
+	}
+	rules, err := scte35.ParseValidationRules(spec)
+	if err != nil {
+		return &httpError{status: http.StatusBadRequest, err: err}
+	}
+
+	body, from, err := h.readBody(w, r)
+	if err != nil {
+		return err
+	}
+	b, err := scte35.SignalBytes(body, from)
+	if err != nil {
+		return &httpError{status: http.StatusUnprocessableEntity, err: err}
+	}
+
+	res := validateResponse{Valid: true, Findings: []scte35.Finding{}}
+	if _, findings := scte35.Validate(b, rules...); findings != nil {
+		res.Findings = findings
+	}
+	var sb strings.Builder
+	for _, f := range res.Findings {
+		res.Valid = res.Valid && f.Severity != scte35.SeverityError
+		sb.WriteString(f.String() + "\n")
+	}
+	if len(res.Findings) == 0 {
+		sb.WriteString("ok\n")
+	}
+	return writeResponse(w, format, &res, sb.String())
+}
+
+// convert handles POST /convert.
+func (h *Handler) convert(w http.ResponseWriter, r *http.Request, _ string) error {
+	to := r.URL.Query().Get("to")
+	if !slices.Contains(scte35.Representations(), to) {
+		return &httpError{status: http.StatusBadRequest, err: fmt.Errorf("unsupported representation: %q", to)}
+	}
+	sis, err := h.readSignal(w, r)
+	if err != nil {
+		return err
+	}
+	b, err := scte35.WriteSignal(sis, to)
+	if err != nil {
+		return &httpError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	return writeBody(w, to, http.StatusOK, b)
+}
+
+// readBody reads the request body, returning it with the representation
+// requested by the from query parameter or Content-Type.
+func (h *Handler) readBody(w http.ResponseWriter, r *http.Request) ([]byte, string, error) {
+	limit := h.MaxRequestBytes
+	if limit == 0 {
+		limit = DefaultMaxRequestBytes
+	}
+	b, err := io.ReadAll(http.MaxBytesReader(w, r.Body, limit))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			return nil, "", &httpError{status: http.StatusRequestEntityTooLarge, err: fmt.Errorf("request body exceeds %d bytes", limit)}
+		}
+		return nil, "", &httpError{status: http.StatusBadRequest, err: err}
+	}
+	if len(b) == 0 {
+		return nil, "", &httpError{status: http.StatusBadRequest, err: errors.New("empty request body")}
+	}
+
+	from := r.URL.Query().Get("from")
+	if from == "" {
+		from = scte35.RepresentationAuto
+		if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "application/octet-stream" {
+			from = scte35.RepresentationBinary
+		}
+	}
+	return b, from, nil
+}
+
+// readSignal reads and decodes the signal in the request body.
+func (h *Handler) readSignal(w http.ResponseWriter, r *http.Request) (*scte35.SpliceInfoSection, error) {
+	b, from, err := h.readBody(w, r)
+	if err != nil {
+		return nil, err
+	}
+	sis, err := scte35.ReadSignal(b, from)
+	if err != nil {
+		return nil, &httpError{status: http.StatusUnprocessableEntity, err: err}
+	}
+	return sis, nil
+}
+
+// httpError is an error with an HTTP status code.
+type httpError struct {
+	status int
+	err    error
+}
+
+// Error returns the error string.
+func (e *httpError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the wrapped error.
+func (e *httpError) Unwrap() error {
+	return e.err
+}
+
+// negotiate returns the response format (scte35.RepresentationJSON,
+// scte35.RepresentationXML or scte35.RepresentationText) for the Accept
+// header.
+func negotiate(accept string) string {
+	for _, part := range strings.Split(accept, ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch mt {
+		case "application/json", "application/*", "*/*":
+			return scte35.RepresentationJSON
+		case "application/xml", "text/xml":
+			return scte35.RepresentationXML
+		case "text/plain", "text/*":
+			return scte35.RepresentationText
+		}
+	}
+	return scte35.RepresentationJSON
+}
+
+// contentType returns the Content-Type of a representation.
+func contentType(representation string) string {
+	switch representation {
+	case scte35.RepresentationJSON:
+		return "application/json"
+	case scte35.RepresentationXML, scte35.RepresentationSignal:
+		return "application/xml"
+	case scte35.RepresentationBinary:
+		return "application/octet-stream"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// writeBody writes b with the Content-Type of the representation.
+func writeBody(w http.ResponseWriter, representation string, status int, b []byte) error {
+	w.Header().Set("Content-Type", contentType(representation))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, err := w.Write(b)
+	return err
+}
+
+// writeResponse writes v as JSON or XML, or text for scte35.RepresentationText.
+func writeResponse(w http.ResponseWriter, format string, v any, text string) error {
+	b, err := marshal(format, v, text)
+	if err != nil {
+		return err
+	}
+	return writeBody(w, format, http.StatusOK, b)
+}
+
+// writeError writes err with the given status.
+func writeError(w http.ResponseWriter, format string, status int, err error) {
+	res := struct {
+		XMLName xml.Name `xml:"Error" json:"-"`
+		Status  int      `xml:"status,attr" json:"status"`
+		Error   string   `xml:",chardata" json:"error"`
+	}{Status: status, Error: err.Error()}
+	b, _ := marshal(format, &res, "Error: "+res.Error+"\n")
+	_ = writeBody(w, format, status, b)
+}
+
+// marshal returns v as JSON or XML, or text for scte35.RepresentationText.
+// JSON and XML are terminated with a newline.
+func marshal(format string, v any, text string) ([]byte, error) {
+	var b []byte
+	var err error
+	switch format {
+	case scte35.RepresentationXML:
+		b, err = xml.MarshalIndent(v, "", "\t")
+	case scte35.RepresentationText:
+		return []byte(text), nil
+	default:
+		b, err = json.MarshalIndent(v, "", "\t")
+	}
+	return append(b, '\n'), err
+}
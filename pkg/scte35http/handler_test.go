@@ -0,0 +1,235 @@
+// Copyright 2021 Comcast Cable Communications Management, LLC
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or   implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// SPDX-License-Identifier: Apache-2.0
+
+package scte35http_test
+
+import (
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Comcast/scte35-go/pkg/scte35"
+	"github.com/Comcast/scte35-go/pkg/scte35http"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	// Sample 14.1 time_signal - Placement Opportunity Start
+	const signal = "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfg=="
+
+	sis, err := scte35.DecodeBase64(signal)
+	require.NoError(t, err)
+	bin, err := base64.StdEncoding.DecodeString(signal)
+	require.NoError(t, err)
+	js, err := scte35.WriteSignal(sis, scte35.RepresentationJSON)
+	require.NoError(t, err)
+
+	cases := map[string]struct {
+		method      string
+		target      string
+		body        string
+		contentType string
+		accept      string
+		status      int
+		respType    string
+		contains    []string
+	}{
+		"Health": {
+			method:   http.MethodGet,
+			target:   "/health",
+			status:   http.StatusOK,
+			respType: "application/json",
+			contains: []string{`"status": "ok"`},
+		},
+		"Health Text": {
+			method:   http.MethodGet,
+			target:   "/health",
+			accept:   "text/plain",
+			status:   http.StatusOK,
+			respType: "text/plain; charset=utf-8",
+			contains: []string{"ok\n"},
+		},
+		"Decode JSON": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			body:     signal,
+			status:   http.StatusOK,
+			respType: "application/json",
+			contains: []string{string(js)},
+		},
+		"Decode XML": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			body:     sis.Hex(),
+			accept:   "text/html, application/xml;q=0.9",
+			status:   http.StatusOK,
+			respType: "application/xml",
+			contains: []string{"<SpliceInfoSection", "<TimeSignal"},
+		},
+		"Decode Text": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			body:     string(bin),
+			accept:   "text/plain",
+			status:   http.StatusOK,
+			respType: "text/plain; charset=utf-8",
+			contains: []string{sis.Table("", "\t")},
+		},
+		"Decode Binary Content-Type": {
+			method:      http.MethodPost,
+			target:      "/decode",
+			body:        string(bin),
+			contentType: "application/octet-stream",
+			status:      http.StatusOK,
+			respType:    "application/json",
+			contains:    []string{`"tier": 4095`},
+		},
+		"Decode Invalid": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			body:     "/DA0AAAA",
+			status:   http.StatusUnprocessableEntity,
+			respType: "application/json",
+			contains: []string{`"status": 422`, `"error": `},
+		},
+		"Decode Empty": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			status:   http.StatusBadRequest,
+			respType: "application/json",
+			contains: []string{"empty request body"},
+		},
+		"Decode Too Large": {
+			method:   http.MethodPost,
+			target:   "/decode",
+			body:     strings.Repeat("A", scte35http.DefaultMaxRequestBytes+1),
+			status:   http.StatusRequestEntityTooLarge,
+			respType: "application/json",
+			contains: []string{"request body exceeds 65536 bytes"},
+		},
+		"Decode Method Not Allowed": {
+			method:   http.MethodGet,
+			target:   "/decode",
+			accept:   "application/xml",
+			status:   http.StatusMethodNotAllowed,
+			respType: "application/xml",
+			contains: []string{`<Error status="405">method not allowed: GET</Error>`},
+		},
+		"Encode": {
+			method:   http.MethodPost,
+			target:   "/encode",
+			body:     string(js),
+			status:   http.StatusOK,
+			respType: "application/json",
+			contains: []string{`"base64": "` + signal + `"`, `"hex": "` + sis.Hex() + `"`},
+		},
+		"Encode Text": {
+			method:   http.MethodPost,
+			target:   "/encode",
+			body:     string(js),
+			accept:   "text/plain",
+			status:   http.StatusOK,
+			respType: "text/plain; charset=utf-8",
+			contains: []string{"Base64: " + signal + "\nHex   : " + sis.Hex() + "\n"},
+		},
+		"Validate": {
+			method:   http.MethodPost,
+			target:   "/validate",
+			body:     signal,
+			status:   http.StatusOK,
+			respType: "application/json",
+			contains: []string{`"valid": true`, `"findings": []`},
+		},
+		"Validate Hex Upper Case Prefix": {
+			method:   http.MethodPost,
+			target:   "/validate",
+			body:     "0X" + strings.ToUpper(sis.Hex()),
+			status:   http.StatusOK,
+			respType: "application/json",
+			contains: []string{`"valid": true`},
+		},
+		"Validate Findings": {
+			method:   http.MethodPost,
+			target:   "/validate?rules=crc",
+			body:     "/DA0AAAAAAAA///wBQb+cr0AUAAeAhxDVUVJSAAAjn/PAAGlmbAICAAAAAAsoKGKNAIAmsnRfw==",
+			accept:   "text/plain",
+			status:   http.StatusOK,
+			respType: "text/plain; charset=utf-8",
+			contains: []string{"error: [crc]"},
+		},
+		"Validate Invalid Rules": {
+			method:   http.MethodPost,
+			target:   "/validate?rules=bogus",
+			body:     signal,
+			status:   http.StatusBadRequest,
+			respType: "application/json",
+			contains: []string{"bogus"},
+		},
+		"Convert": {
+			method:   http.MethodPost,
+			target:   "/convert?to=signal",
+			body:     signal,
+			status:   http.StatusOK,
+			respType: "application/xml",
+			contains: []string{"<Binary>" + signal + "</Binary>"},
+		},
+		"Convert Binary": {
+			method:   http.MethodPost,
+			target:   "/convert?from=hex&to=binary",
+			body:     sis.Hex(),
+			status:   http.StatusOK,
+			respType: "application/octet-stream",
+			contains: []string{string(bin)},
+		},
+		"Convert Invalid": {
+			method:   http.MethodPost,
+			target:   "/convert?to=yaml",
+			body:     signal,
+			status:   http.StatusBadRequest,
+			respType: "application/json",
+			contains: []string{`unsupported representation: \"yaml\"`},
+		},
+		"Not Found": {
+			method:   http.MethodGet,
+			target:   "/",
+			status:   http.StatusNotFound,
+			respType: "application/json",
+			contains: []string{"not found: /"},
+		},
+	}
+
+	for k, c := range cases {
+		t.Run(k, func(t *testing.T) {
+			r := httptest.NewRequest(c.method, c.target, strings.NewReader(c.body))
+			if c.contentType != "" {
+				r.Header.Set("Content-Type", c.contentType)
+			}
+			if c.accept != "" {
+				r.Header.Set("Accept", c.accept)
+			}
+			w := httptest.NewRecorder()
+			scte35http.NewHandler().ServeHTTP(w, r)
+
+			require.Equal(t, c.status, w.Code, w.Body.String())
+			require.Equal(t, c.respType, w.Header().Get("Content-Type"))
+			for _, s := range c.contains {
+				require.Contains(t, w.Body.String(), s)
+			}
+		})
+	}
+}